@@ -0,0 +1,184 @@
+package gotrue
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/ulbqb/gotrue-go/gotrueapi"
+)
+
+// AdminClient calls the admin endpoints of GoTrue. Every request is
+// authenticated with the service role key the client was created with, so
+// it must never be used on behalf of a user.
+type AdminClient struct {
+	api *APIClient
+}
+
+// NewAdminClient creates an admin client authenticated with serviceRoleKey.
+// serviceRoleKey takes precedence over WithAPIKey.
+func NewAdminClient(url, serviceRoleKey string, opts ...Option) *AdminClient {
+	o := newOptions(opts)
+	o.apiKey = serviceRoleKey
+
+	return &AdminClient{
+		api: newAPIClient(url, o),
+	}
+}
+
+// ListUsers returns a page of users.
+func (c *AdminClient) ListUsers(ctx context.Context, params *gotrueapi.AdminListUsersParams) (*gotrueapi.UserList, error) {
+	return c.api.AdminListUsersWithContext(ctx, params)
+}
+
+// GetUser returns the user with provided id.
+func (c *AdminClient) GetUser(ctx context.Context, uid uuid.UUID) (*gotrueapi.User, error) {
+	return c.api.AdminGetUserWithContext(ctx, uid)
+}
+
+// CreateUser creates a user without sending any confirmation.
+func (c *AdminClient) CreateUser(ctx context.Context, params *gotrueapi.AdminCreateUserParams) (*gotrueapi.User, error) {
+	return c.api.AdminCreateUserWithContext(ctx, params)
+}
+
+// UpdateUser updates the user with provided id.
+func (c *AdminClient) UpdateUser(ctx context.Context, uid uuid.UUID, params *gotrueapi.UpdateUserByIdParams) (*gotrueapi.User, error) {
+	return c.api.UpdateUserByIdWithContext(ctx, uid, params)
+}
+
+// DeleteUser deletes the user with provided id.
+func (c *AdminClient) DeleteUser(ctx context.Context, uid uuid.UUID) error {
+	return c.api.AdminDeleteUserWithContext(ctx, uid)
+}
+
+// GenerateLink generates an email action link without sending it.
+func (c *AdminClient) GenerateLink(ctx context.Context, params *gotrueapi.GenerateLinkParams) (*gotrueapi.GenerateLinkResponse, error) {
+	return c.api.AdminGenerateLinkWithContext(ctx, params)
+}
+
+// InviteUserByEmail sends an invite link to the email address.
+func (c *AdminClient) InviteUserByEmail(ctx context.Context, params *gotrueapi.InviteParams) (*gotrueapi.User, error) {
+	return c.api.AdminInviteUserByEmailWithContext(ctx, params)
+}
+
+// ListFactors returns the MFA factors of the user with provided id.
+func (c *AdminClient) ListFactors(ctx context.Context, uid uuid.UUID) ([]gotrueapi.Factor, error) {
+	return c.api.AdminListFactorsWithContext(ctx, uid)
+}
+
+// DeleteFactor unenrolls an MFA factor of the user with provided id.
+func (c *AdminClient) DeleteFactor(ctx context.Context, uid, factorID uuid.UUID) error {
+	return c.api.AdminDeleteFactorWithContext(ctx, uid, factorID)
+}
+
+// ListSSOProviders returns the SSO identity providers.
+func (c *AdminClient) ListSSOProviders(ctx context.Context) ([]gotrueapi.SSOProvider, error) {
+	return c.api.AdminListSSOProvidersWithContext(ctx)
+}
+
+// CreateSSOProvider registers a SAML identity provider.
+func (c *AdminClient) CreateSSOProvider(ctx context.Context, params *gotrueapi.CreateSSOProviderParams) (*gotrueapi.SSOProvider, error) {
+	return c.api.AdminCreateSSOProviderWithContext(ctx, params)
+}
+
+func (c *AdminClient) GetSSOProvider(ctx context.Context, id uuid.UUID) (*gotrueapi.SSOProvider, error) {
+	return c.api.AdminGetSSOProviderWithContext(ctx, id)
+}
+
+func (c *AdminClient) UpdateSSOProvider(ctx context.Context, id uuid.UUID, params *gotrueapi.UpdateSSOProviderParams) (*gotrueapi.SSOProvider, error) {
+	return c.api.AdminUpdateSSOProviderWithContext(ctx, id, params)
+}
+
+func (c *AdminClient) DeleteSSOProvider(ctx context.Context, id uuid.UUID) error {
+	return c.api.AdminDeleteSSOProviderWithContext(ctx, id)
+}
+
+// usersPerPage is the page size UserIterator requests.
+const usersPerPage = 50
+
+// Users returns an iterator over all users matching filter. An empty filter
+// matches every user.
+func (c *AdminClient) Users(ctx context.Context, filter string) *UserIterator {
+	return &UserIterator{
+		ctx: ctx,
+		api: c.api,
+		params: gotrueapi.AdminListUsersParams{
+			Page:    1,
+			PerPage: usersPerPage,
+			Filter:  filter,
+		},
+	}
+}
+
+// UserIterator walks the pages of the admin user listing.
+//
+//	it := admin.Users(ctx, "")
+//	for it.Next() {
+//		user := it.User()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type UserIterator struct {
+	ctx    context.Context
+	api    *APIClient
+	params gotrueapi.AdminListUsersParams
+
+	users []gotrueapi.User
+	user  *gotrueapi.User
+	done  bool
+	err   error
+}
+
+// Next advances to the next user, fetching the next page when needed. It
+// returns false when there are no more users or an error occurred.
+func (it *UserIterator) Next() bool {
+	for len(it.users) == 0 {
+		if it.done || it.err != nil {
+			it.user = nil
+			return false
+		}
+		it.fetch()
+	}
+
+	it.user = &it.users[0]
+	it.users = it.users[1:]
+	return true
+}
+
+// User returns the current user.
+func (it *UserIterator) User() *gotrueapi.User {
+	return it.user
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *UserIterator) Err() error {
+	return it.err
+}
+
+func (it *UserIterator) fetch() {
+	if err := it.ctx.Err(); err != nil {
+		it.err = err
+		return
+	}
+
+	list, err := it.api.AdminListUsersWithContext(it.ctx, &it.params)
+	if err != nil {
+		it.err = err
+		return
+	}
+	it.users = list.Users
+
+	switch {
+	case list.NextPage > 0:
+		it.done = list.NextPage <= it.params.Page
+		it.params.Page = list.NextPage
+	case list.Total > 0:
+		it.done = it.params.Page*it.params.PerPage >= list.Total
+		it.params.Page++
+	default:
+		it.done = len(list.Users) < it.params.PerPage
+		it.params.Page++
+	}
+}
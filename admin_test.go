@@ -0,0 +1,164 @@
+package gotrue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/ulbqb/gotrue-go/gotrueapi"
+	"github.com/ulbqb/gotrue-go/internal/testdata"
+)
+
+func TestAdminClient(t *testing.T) {
+	uid := uuid.New()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer "+testdata.AdminToken {
+			t.Errorf("Authorization = %s, want service role key", got)
+		}
+		if got := r.Header.Get("apikey"); got != testdata.AdminToken {
+			t.Errorf("apikey = %s, want service role key", got)
+		}
+		if r.URL.Path != "/admin/users/"+uid.String()+"/factors" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+		_, _ = w.Write([]byte(`[{"id":"` + uuid.NewString() + `","factor_type":"totp","status":"verified"}]`))
+	}))
+	defer server.Close()
+
+	client := NewAdminClient(server.URL, testdata.AdminToken, WithAPIKey("anon-key"))
+
+	factors, err := client.ListFactors(context.Background(), uid)
+	if err != nil {
+		t.Errorf("ListFactors() error = %v", err)
+		return
+	}
+	if len(factors) != 1 || factors[0].FactorType != "totp" {
+		t.Errorf("ListFactors() = %v", factors)
+	}
+}
+
+func TestAdminClient_Users(t *testing.T) {
+	const total = 120
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var page, perPage int
+		fmt.Sscan(r.URL.Query().Get("page"), &page)
+		fmt.Sscan(r.URL.Query().Get("per_page"), &perPage)
+
+		var list gotrueapi.UserList
+		for i := (page - 1) * perPage; i < page*perPage && i < total; i++ {
+			list.Users = append(list.Users, gotrueapi.User{ID: uuid.New()})
+		}
+
+		w.Header().Set("X-Total-Count", fmt.Sprint(total))
+		if page*perPage < total {
+			w.Header().Set("Link", fmt.Sprintf(
+				`</admin/users?page=%d&per_page=%d>; rel="next", </admin/users?page=3&per_page=%d>; rel="last"`,
+				page+1, perPage, perPage))
+		}
+		_ = json.NewEncoder(w).Encode(&list)
+	}))
+	defer server.Close()
+
+	client := NewAdminClient(server.URL, testdata.AdminToken)
+
+	t.Run("walks every page", func(t *testing.T) {
+		var n int
+		it := client.Users(context.Background(), "")
+		for it.Next() {
+			if it.User() == nil {
+				t.Errorf("User() returns nil")
+				return
+			}
+			n++
+		}
+		if err := it.Err(); err != nil {
+			t.Errorf("Err() = %v", err)
+			return
+		}
+		if n != total {
+			t.Errorf("iterated %d users, want = %d", n, total)
+		}
+	})
+
+	t.Run("stops on cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		it := client.Users(ctx, "")
+		if it.Next() {
+			t.Errorf("Next() returns true after cancellation")
+		}
+		if it.Err() != context.Canceled {
+			t.Errorf("Err() = %v, want = %v", it.Err(), context.Canceled)
+		}
+	})
+}
+
+func TestAdminClient_SSOProviders(t *testing.T) {
+	id := uuid.New()
+	provider := gotrueapi.SSOProvider{
+		ID:      id,
+		SAML:    gotrueapi.SAMLProvider{EntityID: "https://idp.example.com", MetadataURL: "https://idp.example.com/metadata"},
+		Domains: []gotrueapi.SSODomain{{Domain: "example.com"}},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /admin/sso/providers":
+			var params gotrueapi.CreateSSOProviderParams
+			_ = json.NewDecoder(r.Body).Decode(&params)
+			if params.Type != gotrueapi.SSOProviderTypeSAML || len(params.Domains) != 1 || params.AttributeMapping.Keys["email"].Name != "mail" {
+				t.Errorf("CreateSSOProvider() body = %+v", params)
+			}
+			_ = json.NewEncoder(w).Encode(&provider)
+		case "GET /admin/sso/providers":
+			_ = json.NewEncoder(w).Encode(&gotrueapi.SSOProviderList{Items: []gotrueapi.SSOProvider{provider}})
+		case "PUT /admin/sso/providers/" + id.String():
+			_ = json.NewEncoder(w).Encode(&provider)
+		case "DELETE /admin/sso/providers/" + id.String():
+			_ = json.NewEncoder(w).Encode(&provider)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewAdminClient(server.URL, testdata.AdminToken)
+	ctx := context.Background()
+
+	created, err := client.CreateSSOProvider(ctx, &gotrueapi.CreateSSOProviderParams{
+		Type:        gotrueapi.SSOProviderTypeSAML,
+		MetadataURL: "https://idp.example.com/metadata",
+		Domains:     []string{"example.com"},
+		AttributeMapping: &gotrueapi.SAMLAttributeMapping{
+			Keys: map[string]gotrueapi.SAMLAttribute{"email": {Name: "mail"}},
+		},
+	})
+	if err != nil {
+		t.Errorf("CreateSSOProvider() error = %v", err)
+		return
+	}
+	if created.ID != id || created.SAML.EntityID != provider.SAML.EntityID {
+		t.Errorf("CreateSSOProvider() = %+v", created)
+	}
+
+	providers, err := client.ListSSOProviders(ctx)
+	if err != nil || len(providers) != 1 || providers[0].Domains[0].Domain != "example.com" {
+		t.Errorf("ListSSOProviders() = %+v, %v", providers, err)
+	}
+
+	if _, err := client.UpdateSSOProvider(ctx, id, &gotrueapi.UpdateSSOProviderParams{Domains: []string{"example.org"}}); err != nil {
+		t.Errorf("UpdateSSOProvider() error = %v", err)
+	}
+	if err := client.DeleteSSOProvider(ctx, id); err != nil {
+		t.Errorf("DeleteSSOProvider() error = %v", err)
+	}
+}
@@ -1,10 +1,13 @@
 package gotrue
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,15 +21,28 @@ type APIClient struct {
 	baseURL     string
 	baseHeaders Headers
 	http        *http.Client
+	retryPolicy *RetryPolicy
 }
 
-func NewAPIClient(url string) *APIClient {
+func NewAPIClient(url string, opts ...Option) *APIClient {
+	return newAPIClient(url, newOptions(opts))
+}
+
+func newAPIClient(url string, o *options) *APIClient {
+	headers := Headers{}
+	if len(o.apiKey) > 0 {
+		headers["apikey"] = o.apiKey
+		headers["Authorization"] = "Bearer " + o.apiKey
+	}
+	if len(o.userAgent) > 0 {
+		headers["User-Agent"] = o.userAgent
+	}
+
 	return &APIClient{
 		baseURL:     url,
-		baseHeaders: Headers{},
-		http: &http.Client{
-			Timeout: 5 * time.Second,
-		},
+		baseHeaders: headers,
+		http:        o.newHTTPClient(),
+		retryPolicy: o.retryPolicy,
 	}
 }
 
@@ -38,61 +54,95 @@ func (c *APIClient) AppendHeaders(headers Headers) {
 
 func (c *APIClient) do(req *http.Request, err error) func(out interface{}) error {
 	return func(out interface{}) error {
+		_, err := c.doWithHeader(req, err)(out)
+		return err
+	}
+}
+
+// doWithHeader is like do but also returns the response headers.
+func (c *APIClient) doWithHeader(req *http.Request, err error) func(out interface{}) (http.Header, error) {
+	return func(out interface{}) (http.Header, error) {
 		if err != nil {
-			return err
+			return nil, err
 		}
 
+		sentAt := time.Now()
 		resp, err := c.http.Do(req)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		defer resp.Body.Close()
 
 		if resp.StatusCode >= 400 {
-			var apiErr gotrueapi.Error
-			err = json.NewDecoder(resp.Body).Decode(&apiErr)
+			body, err := io.ReadAll(resp.Body)
 			if err != nil {
-				return fmt.Errorf(
-					"api: failed to decode error (%d): %w",
-					resp.StatusCode,
-					err,
-				)
+				return nil, errors.Wrap(err, "api: failed to read error")
 			}
-			return &apiErr
+			return nil, gotrueapi.NewError(resp.StatusCode, resp.Header, body)
 		}
 
 		if out != nil {
 			err = json.NewDecoder(resp.Body).Decode(out)
 			if err != nil {
-				return errors.Wrap(err, "api: failed to decode response")
+				return nil, errors.Wrap(err, "api: failed to decode response")
+			}
+			// expires_in counts from when the server issued the session,
+			// so the send time errs on the side of early expiry.
+			if session, ok := out.(*gotrueapi.Session); ok {
+				fillExpiresAt(session, sentAt)
 			}
 		}
 
-		return nil
+		return resp.Header, nil
 	}
 }
 
 func (c *APIClient) SignUp(params *gotrueapi.SignUpParams) (*gotrueapi.Session, error) {
+	return c.SignUpWithContext(context.Background(), params)
+}
+
+func (c *APIClient) SignUpWithContext(ctx context.Context, params *gotrueapi.SignUpParams) (*gotrueapi.Session, error) {
 	var resp struct {
 		*gotrueapi.Session
 		*gotrueapi.User
 	}
 
-	err := c.do(gotrueapi.SignUp(c.baseURL, c.baseHeaders, params))(&resp)
+	err := c.do(gotrueapi.SignUpWithContext(ctx, c.baseURL, c.baseHeaders, params))(&resp)
 	if err != nil {
 		return nil, err
 	}
 
 	if resp.Session != nil && len(resp.Session.Token) > 0 {
+		fillExpiresAt(resp.Session, time.Now())
 		return resp.Session, nil
 	}
 
 	return &gotrueapi.Session{User: resp.User}, nil
 }
 
+func (c *APIClient) SignUpAnonymously(params *gotrueapi.AnonymousSignUpParams) (*gotrueapi.Session, error) {
+	return c.SignUpAnonymouslyWithContext(context.Background(), params)
+}
+
+func (c *APIClient) SignUpAnonymouslyWithContext(ctx context.Context, params *gotrueapi.AnonymousSignUpParams) (*gotrueapi.Session, error) {
+	var resp gotrueapi.Session
+
+	err := c.do(gotrueapi.SignUpAnonymouslyWithContext(ctx, c.baseURL, c.baseHeaders, params))(&resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
 func (c *APIClient) IssueTokenWithPassword(params *gotrueapi.TokenWithPasswordGrantParams) (*gotrueapi.Session, error) {
+	return c.IssueTokenWithPasswordWithContext(context.Background(), params)
+}
+
+func (c *APIClient) IssueTokenWithPasswordWithContext(ctx context.Context, params *gotrueapi.TokenWithPasswordGrantParams) (*gotrueapi.Session, error) {
 	var resp gotrueapi.Session
 
-	err := c.do(gotrueapi.TokenWithPasswordGrant(c.baseURL, c.baseHeaders, params))(&resp)
+	err := c.do(gotrueapi.TokenWithPasswordGrantWithContext(ctx, c.baseURL, c.baseHeaders, params))(&resp)
 	if err != nil {
 		return nil, err
 	}
@@ -101,9 +151,13 @@ func (c *APIClient) IssueTokenWithPassword(params *gotrueapi.TokenWithPasswordGr
 }
 
 func (c *APIClient) IssueTokenWithRefreshToken(params *gotrueapi.TokenWithRefreshTokenGrantParams) (*gotrueapi.Session, error) {
+	return c.IssueTokenWithRefreshTokenWithContext(context.Background(), params)
+}
+
+func (c *APIClient) IssueTokenWithRefreshTokenWithContext(ctx context.Context, params *gotrueapi.TokenWithRefreshTokenGrantParams) (*gotrueapi.Session, error) {
 	var resp gotrueapi.Session
 
-	err := c.do(gotrueapi.TokenWithRefreshTokenGrant(c.baseURL, c.baseHeaders, params))(&resp)
+	err := c.doRetry(gotrueapi.TokenWithRefreshTokenGrantWithContext(ctx, c.baseURL, c.baseHeaders, params))(&resp)
 	if err != nil {
 		return nil, err
 	}
@@ -112,9 +166,28 @@ func (c *APIClient) IssueTokenWithRefreshToken(params *gotrueapi.TokenWithRefres
 }
 
 func (c *APIClient) IssueTokenWithIDToken(params *gotrueapi.TokenWithIDTokenGrantParams) (*gotrueapi.Session, error) {
+	return c.IssueTokenWithIDTokenWithContext(context.Background(), params)
+}
+
+func (c *APIClient) IssueTokenWithIDTokenWithContext(ctx context.Context, params *gotrueapi.TokenWithIDTokenGrantParams) (*gotrueapi.Session, error) {
 	var resp gotrueapi.Session
 
-	err := c.do(gotrueapi.TokenWithIDTokenGrant(c.baseURL, c.baseHeaders, params))(&resp)
+	err := c.do(gotrueapi.TokenWithIDTokenGrantWithContext(ctx, c.baseURL, c.baseHeaders, params))(&resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+func (c *APIClient) IssueTokenWithPKCE(params *gotrueapi.TokenWithPKCEGrantParams) (*gotrueapi.Session, error) {
+	return c.IssueTokenWithPKCEWithContext(context.Background(), params)
+}
+
+func (c *APIClient) IssueTokenWithPKCEWithContext(ctx context.Context, params *gotrueapi.TokenWithPKCEGrantParams) (*gotrueapi.Session, error) {
+	var resp gotrueapi.Session
+
+	err := c.do(gotrueapi.TokenWithPKCEGrantWithContext(ctx, c.baseURL, c.baseHeaders, params))(&resp)
 	if err != nil {
 		return nil, err
 	}
@@ -123,25 +196,75 @@ func (c *APIClient) IssueTokenWithIDToken(params *gotrueapi.TokenWithIDTokenGran
 }
 
 func (c *APIClient) SignOut(accessToken string) error {
-	return c.do(gotrueapi.Logout(c.baseURL, c.createRequestHeaders(accessToken)))(nil)
+	return c.SignOutWithContext(context.Background(), accessToken)
+}
+
+func (c *APIClient) SignOutWithContext(ctx context.Context, accessToken string) error {
+	return c.do(gotrueapi.LogoutWithContext(ctx, c.baseURL, c.createRequestHeaders(accessToken)))(nil)
 }
 
 func (c *APIClient) SendMagicLinkEmail(params *gotrueapi.MagicLinkParams) error {
-	return c.do(gotrueapi.MagicLink(c.baseURL, c.baseHeaders, params))(nil)
+	return c.SendMagicLinkEmailWithContext(context.Background(), params)
+}
+
+func (c *APIClient) SendMagicLinkEmailWithContext(ctx context.Context, params *gotrueapi.MagicLinkParams) error {
+	return c.do(gotrueapi.MagicLinkWithContext(ctx, c.baseURL, c.baseHeaders, params))(nil)
 }
 
 func (c *APIClient) SendMobileOTP(params *gotrueapi.OTPParams) error {
-	return c.do(gotrueapi.OTP(c.baseURL, c.baseHeaders, params))(nil)
+	return c.SendMobileOTPWithContext(context.Background(), params)
+}
+
+func (c *APIClient) SendMobileOTPWithContext(ctx context.Context, params *gotrueapi.OTPParams) error {
+	return c.do(gotrueapi.OTPWithContext(ctx, c.baseURL, c.baseHeaders, params))(nil)
 }
 
 func (c *APIClient) ResetPasswordForEmail(params *gotrueapi.RecoverParams) error {
-	return c.do(gotrueapi.Recover(c.baseURL, c.baseHeaders, params))(nil)
+	return c.ResetPasswordForEmailWithContext(context.Background(), params)
+}
+
+func (c *APIClient) ResetPasswordForEmailWithContext(ctx context.Context, params *gotrueapi.RecoverParams) error {
+	return c.do(gotrueapi.RecoverWithContext(ctx, c.baseURL, c.baseHeaders, params))(nil)
+}
+
+func (c *APIClient) Verify(params *gotrueapi.VerifyParams) (*gotrueapi.Session, error) {
+	return c.VerifyWithContext(context.Background(), params)
+}
+
+func (c *APIClient) VerifyWithContext(ctx context.Context, params *gotrueapi.VerifyParams) (*gotrueapi.Session, error) {
+	var resp gotrueapi.Session
+
+	err := c.do(gotrueapi.VerifyWithContext(ctx, c.baseURL, c.baseHeaders, params))(&resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+func (c *APIClient) GetSettings() (*gotrueapi.Settings, error) {
+	return c.GetSettingsWithContext(context.Background())
+}
+
+func (c *APIClient) GetSettingsWithContext(ctx context.Context) (*gotrueapi.Settings, error) {
+	var resp gotrueapi.Settings
+
+	err := c.doRetry(gotrueapi.GetSettingsWithContext(ctx, c.baseURL, c.baseHeaders))(&resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
 }
 
 func (c *APIClient) GetUser(accessToken string) (*gotrueapi.User, error) {
+	return c.GetUserWithContext(context.Background(), accessToken)
+}
+
+func (c *APIClient) GetUserWithContext(ctx context.Context, accessToken string) (*gotrueapi.User, error) {
 	var resp gotrueapi.User
 
-	err := c.do(gotrueapi.GetUser(c.baseURL, c.createRequestHeaders(accessToken)))(&resp)
+	err := c.doRetry(gotrueapi.GetUserWithContext(ctx, c.baseURL, c.createRequestHeaders(accessToken)))(&resp)
 	if err != nil {
 		return nil, err
 	}
@@ -150,9 +273,28 @@ func (c *APIClient) GetUser(accessToken string) (*gotrueapi.User, error) {
 }
 
 func (c *APIClient) UpdateUser(accessToken string, params *gotrueapi.PutUserParams) (*gotrueapi.User, error) {
+	return c.UpdateUserWithContext(context.Background(), accessToken, params)
+}
+
+func (c *APIClient) UpdateUserWithContext(ctx context.Context, accessToken string, params *gotrueapi.PutUserParams) (*gotrueapi.User, error) {
 	var resp gotrueapi.User
 
-	err := c.do(gotrueapi.PutUser(c.baseURL, c.createRequestHeaders(accessToken), params))(&resp)
+	err := c.do(gotrueapi.PutUserWithContext(ctx, c.baseURL, c.createRequestHeaders(accessToken), params))(&resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+func (c *APIClient) EnrollFactor(accessToken string, params *gotrueapi.EnrollFactorParams) (*gotrueapi.EnrollFactorResponse, error) {
+	return c.EnrollFactorWithContext(context.Background(), accessToken, params)
+}
+
+func (c *APIClient) EnrollFactorWithContext(ctx context.Context, accessToken string, params *gotrueapi.EnrollFactorParams) (*gotrueapi.EnrollFactorResponse, error) {
+	var resp gotrueapi.EnrollFactorResponse
+
+	err := c.do(gotrueapi.EnrollFactorWithContext(ctx, c.baseURL, c.createRequestHeaders(accessToken), params))(&resp)
 	if err != nil {
 		return nil, err
 	}
@@ -160,10 +302,239 @@ func (c *APIClient) UpdateUser(accessToken string, params *gotrueapi.PutUserPara
 	return &resp, nil
 }
 
+func (c *APIClient) ChallengeFactor(accessToken string, factorID uuid.UUID) (*gotrueapi.Challenge, error) {
+	return c.ChallengeFactorWithContext(context.Background(), accessToken, factorID)
+}
+
+func (c *APIClient) ChallengeFactorWithContext(ctx context.Context, accessToken string, factorID uuid.UUID) (*gotrueapi.Challenge, error) {
+	var resp gotrueapi.Challenge
+
+	err := c.do(gotrueapi.ChallengeFactorWithContext(ctx, c.baseURL, c.createRequestHeaders(accessToken), factorID))(&resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+func (c *APIClient) VerifyFactor(accessToken string, factorID uuid.UUID, params *gotrueapi.VerifyFactorParams) (*gotrueapi.Session, error) {
+	return c.VerifyFactorWithContext(context.Background(), accessToken, factorID, params)
+}
+
+func (c *APIClient) VerifyFactorWithContext(ctx context.Context, accessToken string, factorID uuid.UUID, params *gotrueapi.VerifyFactorParams) (*gotrueapi.Session, error) {
+	var resp gotrueapi.Session
+
+	err := c.do(gotrueapi.VerifyFactorWithContext(ctx, c.baseURL, c.createRequestHeaders(accessToken), factorID, params))(&resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+func (c *APIClient) UnenrollFactor(accessToken string, factorID uuid.UUID) error {
+	return c.UnenrollFactorWithContext(context.Background(), accessToken, factorID)
+}
+
+func (c *APIClient) UnenrollFactorWithContext(ctx context.Context, accessToken string, factorID uuid.UUID) error {
+	return c.do(gotrueapi.UnenrollFactorWithContext(ctx, c.baseURL, c.createRequestHeaders(accessToken), factorID))(nil)
+}
+
+func (c *APIClient) Reauthenticate(accessToken string) error {
+	return c.ReauthenticateWithContext(context.Background(), accessToken)
+}
+
+func (c *APIClient) ReauthenticateWithContext(ctx context.Context, accessToken string) error {
+	return c.do(gotrueapi.ReauthenticateWithContext(ctx, c.baseURL, c.createRequestHeaders(accessToken)))(nil)
+}
+
+// LinkIdentity returns the URL to send the user to for linking an identity.
+func (c *APIClient) LinkIdentity(accessToken string, params *gotrueapi.LinkIdentityParams) (string, error) {
+	return c.LinkIdentityWithContext(context.Background(), accessToken, params)
+}
+
+func (c *APIClient) LinkIdentityWithContext(ctx context.Context, accessToken string, params *gotrueapi.LinkIdentityParams) (string, error) {
+	var resp gotrueapi.LinkIdentityResponse
+	err := c.do(gotrueapi.LinkIdentityWithContext(ctx, c.baseURL, c.createRequestHeaders(accessToken), params))(&resp)
+	if err != nil {
+		return "", err
+	}
+	return resp.URL, nil
+}
+
+func (c *APIClient) UnlinkIdentity(accessToken string, identityID uuid.UUID) error {
+	return c.UnlinkIdentityWithContext(context.Background(), accessToken, identityID)
+}
+
+func (c *APIClient) UnlinkIdentityWithContext(ctx context.Context, accessToken string, identityID uuid.UUID) error {
+	return c.do(gotrueapi.UnlinkIdentityWithContext(ctx, c.baseURL, c.createRequestHeaders(accessToken), identityID))(nil)
+}
+
 func (c *APIClient) UpdateUserById(uid uuid.UUID, params *gotrueapi.UpdateUserByIdParams) (*gotrueapi.User, error) {
+	return c.UpdateUserByIdWithContext(context.Background(), uid, params)
+}
+
+func (c *APIClient) UpdateUserByIdWithContext(ctx context.Context, uid uuid.UUID, params *gotrueapi.UpdateUserByIdParams) (*gotrueapi.User, error) {
+	var resp gotrueapi.User
+
+	err := c.do(gotrueapi.UpdateUserByIdWithContext(ctx, c.baseURL, c.baseHeaders, uid, params))(&resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+func (c *APIClient) AdminListUsers(params *gotrueapi.AdminListUsersParams) (*gotrueapi.UserList, error) {
+	return c.AdminListUsersWithContext(context.Background(), params)
+}
+
+func (c *APIClient) AdminListUsersWithContext(ctx context.Context, params *gotrueapi.AdminListUsersParams) (*gotrueapi.UserList, error) {
+	var resp gotrueapi.UserList
+
+	header, err := c.doRetryWithHeader(gotrueapi.AdminListUsersWithContext(ctx, c.baseURL, c.baseHeaders, params))(&resp)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Total, _ = strconv.Atoi(header.Get("X-Total-Count"))
+	resp.NextPage = nextPageFromLink(header.Get("Link"))
+
+	return &resp, nil
+}
+
+func (c *APIClient) AdminGetUser(uid uuid.UUID) (*gotrueapi.User, error) {
+	return c.AdminGetUserWithContext(context.Background(), uid)
+}
+
+func (c *APIClient) AdminGetUserWithContext(ctx context.Context, uid uuid.UUID) (*gotrueapi.User, error) {
 	var resp gotrueapi.User
 
-	err := c.do(gotrueapi.UpdateUserById(c.baseURL, c.baseHeaders, uid, params))(&resp)
+	err := c.doRetry(gotrueapi.AdminGetUserWithContext(ctx, c.baseURL, c.baseHeaders, uid))(&resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+func (c *APIClient) AdminCreateUser(params *gotrueapi.AdminCreateUserParams) (*gotrueapi.User, error) {
+	return c.AdminCreateUserWithContext(context.Background(), params)
+}
+
+func (c *APIClient) AdminCreateUserWithContext(ctx context.Context, params *gotrueapi.AdminCreateUserParams) (*gotrueapi.User, error) {
+	var resp gotrueapi.User
+
+	err := c.do(gotrueapi.AdminCreateUserWithContext(ctx, c.baseURL, c.baseHeaders, params))(&resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+func (c *APIClient) AdminDeleteUser(uid uuid.UUID) error {
+	return c.AdminDeleteUserWithContext(context.Background(), uid)
+}
+
+func (c *APIClient) AdminDeleteUserWithContext(ctx context.Context, uid uuid.UUID) error {
+	return c.do(gotrueapi.AdminDeleteUserWithContext(ctx, c.baseURL, c.baseHeaders, uid))(nil)
+}
+
+func (c *APIClient) AdminInviteUserByEmail(params *gotrueapi.InviteParams) (*gotrueapi.User, error) {
+	return c.AdminInviteUserByEmailWithContext(context.Background(), params)
+}
+
+func (c *APIClient) AdminInviteUserByEmailWithContext(ctx context.Context, params *gotrueapi.InviteParams) (*gotrueapi.User, error) {
+	var resp gotrueapi.User
+
+	err := c.do(gotrueapi.InviteWithContext(ctx, c.baseURL, c.baseHeaders, params))(&resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+func (c *APIClient) AdminGenerateLink(params *gotrueapi.GenerateLinkParams) (*gotrueapi.GenerateLinkResponse, error) {
+	return c.AdminGenerateLinkWithContext(context.Background(), params)
+}
+
+func (c *APIClient) AdminGenerateLinkWithContext(ctx context.Context, params *gotrueapi.GenerateLinkParams) (*gotrueapi.GenerateLinkResponse, error) {
+	var resp gotrueapi.GenerateLinkResponse
+
+	err := c.do(gotrueapi.AdminGenerateLinkWithContext(ctx, c.baseURL, c.baseHeaders, params))(&resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+func (c *APIClient) AdminListFactors(uid uuid.UUID) ([]gotrueapi.Factor, error) {
+	return c.AdminListFactorsWithContext(context.Background(), uid)
+}
+
+func (c *APIClient) AdminListFactorsWithContext(ctx context.Context, uid uuid.UUID) ([]gotrueapi.Factor, error) {
+	var resp []gotrueapi.Factor
+
+	err := c.doRetry(gotrueapi.AdminListFactorsWithContext(ctx, c.baseURL, c.baseHeaders, uid))(&resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (c *APIClient) AdminDeleteFactor(uid, factorID uuid.UUID) error {
+	return c.AdminDeleteFactorWithContext(context.Background(), uid, factorID)
+}
+
+func (c *APIClient) AdminDeleteFactorWithContext(ctx context.Context, uid, factorID uuid.UUID) error {
+	return c.do(gotrueapi.AdminDeleteFactorWithContext(ctx, c.baseURL, c.baseHeaders, uid, factorID))(nil)
+}
+
+// SSO returns the URL to send the user to for signing in with the identity
+// provider.
+func (c *APIClient) SSO(params *gotrueapi.SSOParams) (string, error) {
+	return c.SSOWithContext(context.Background(), params)
+}
+
+func (c *APIClient) SSOWithContext(ctx context.Context, params *gotrueapi.SSOParams) (string, error) {
+	p := *params
+	p.SkipHTTPRedirect = true
+
+	var resp gotrueapi.SSOResponse
+	err := c.do(gotrueapi.SSOWithContext(ctx, c.baseURL, c.baseHeaders, &p))(&resp)
+	if err != nil {
+		return "", err
+	}
+	return resp.URL, nil
+}
+
+func (c *APIClient) AdminListSSOProviders() ([]gotrueapi.SSOProvider, error) {
+	return c.AdminListSSOProvidersWithContext(context.Background())
+}
+
+func (c *APIClient) AdminListSSOProvidersWithContext(ctx context.Context) ([]gotrueapi.SSOProvider, error) {
+	var resp gotrueapi.SSOProviderList
+
+	err := c.doRetry(gotrueapi.AdminListSSOProvidersWithContext(ctx, c.baseURL, c.baseHeaders))(&resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Items, nil
+}
+
+func (c *APIClient) AdminCreateSSOProvider(params *gotrueapi.CreateSSOProviderParams) (*gotrueapi.SSOProvider, error) {
+	return c.AdminCreateSSOProviderWithContext(context.Background(), params)
+}
+
+func (c *APIClient) AdminCreateSSOProviderWithContext(ctx context.Context, params *gotrueapi.CreateSSOProviderParams) (*gotrueapi.SSOProvider, error) {
+	var resp gotrueapi.SSOProvider
+
+	err := c.do(gotrueapi.AdminCreateSSOProviderWithContext(ctx, c.baseURL, c.baseHeaders, params))(&resp)
 	if err != nil {
 		return nil, err
 	}
@@ -171,21 +542,106 @@ func (c *APIClient) UpdateUserById(uid uuid.UUID, params *gotrueapi.UpdateUserBy
 	return &resp, nil
 }
 
+func (c *APIClient) AdminGetSSOProvider(id uuid.UUID) (*gotrueapi.SSOProvider, error) {
+	return c.AdminGetSSOProviderWithContext(context.Background(), id)
+}
+
+func (c *APIClient) AdminGetSSOProviderWithContext(ctx context.Context, id uuid.UUID) (*gotrueapi.SSOProvider, error) {
+	var resp gotrueapi.SSOProvider
+
+	err := c.doRetry(gotrueapi.AdminGetSSOProviderWithContext(ctx, c.baseURL, c.baseHeaders, id))(&resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+func (c *APIClient) AdminUpdateSSOProvider(id uuid.UUID, params *gotrueapi.UpdateSSOProviderParams) (*gotrueapi.SSOProvider, error) {
+	return c.AdminUpdateSSOProviderWithContext(context.Background(), id, params)
+}
+
+func (c *APIClient) AdminUpdateSSOProviderWithContext(ctx context.Context, id uuid.UUID, params *gotrueapi.UpdateSSOProviderParams) (*gotrueapi.SSOProvider, error) {
+	var resp gotrueapi.SSOProvider
+
+	err := c.do(gotrueapi.AdminUpdateSSOProviderWithContext(ctx, c.baseURL, c.baseHeaders, id, params))(&resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+func (c *APIClient) AdminDeleteSSOProvider(id uuid.UUID) error {
+	return c.AdminDeleteSSOProviderWithContext(context.Background(), id)
+}
+
+func (c *APIClient) AdminDeleteSSOProviderWithContext(ctx context.Context, id uuid.UUID) error {
+	return c.do(gotrueapi.AdminDeleteSSOProviderWithContext(ctx, c.baseURL, c.baseHeaders, id))(nil)
+}
+
 func (c *APIClient) GetProviderSignInURL(provider Provider, redirectTo, scopes string) string {
+	return c.authorizeURL("/authorize", provider, redirectTo, scopes, "")
+}
+
+// GetProviderSignInURLWithCodeChallenge is like GetProviderSignInURL but for
+// the PKCE flow.
+func (c *APIClient) GetProviderSignInURLWithCodeChallenge(provider Provider, redirectTo, scopes, codeChallenge string) string {
+	return c.authorizeURL("/authorize", provider, redirectTo, scopes, codeChallenge)
+}
+
+func (c *APIClient) authorizeURL(path string, provider Provider, redirectTo, scopes, codeChallenge string) string {
 	pathBuf := bytebufferpool.Get()
 	defer bytebufferpool.Put(pathBuf)
 
 	pathBuf.B = append(pathBuf.B, c.baseURL...)
-	pathBuf.B = append(pathBuf.B, "/authorize?provider="...)
+	pathBuf.B = append(pathBuf.B, path...)
+	pathBuf.B = append(pathBuf.B, "?provider="...)
 	pathBuf.B = append(pathBuf.B, url.QueryEscape(string(provider))...)
 	pathBuf.B = append(pathBuf.B, "&redirect_to="...)
 	pathBuf.B = append(pathBuf.B, redirectTo...)
 	pathBuf.B = append(pathBuf.B, "&scopes="...)
 	pathBuf.B = append(pathBuf.B, scopes...)
+	if len(codeChallenge) > 0 {
+		pathBuf.B = append(pathBuf.B, "&code_challenge="...)
+		pathBuf.B = append(pathBuf.B, url.QueryEscape(codeChallenge)...)
+		pathBuf.B = append(pathBuf.B, "&code_challenge_method="...)
+		pathBuf.B = append(pathBuf.B, gotrueapi.CodeChallengeMethodS256...)
+	}
 
 	return string(pathBuf.B)
 }
 
+// nextPageFromLink returns the page of the "next" relation in a Link header,
+// or 0 if there is none.
+func nextPageFromLink(link string) int {
+	for _, part := range strings.Split(link, ",") {
+		segments := strings.Split(part, ";")
+		if len(segments) < 2 {
+			continue
+		}
+
+		var isNext bool
+		for _, param := range segments[1:] {
+			if strings.TrimSpace(param) == `rel="next"` {
+				isNext = true
+			}
+		}
+		if !isNext {
+			continue
+		}
+
+		target := strings.Trim(strings.TrimSpace(segments[0]), "<>")
+		u, err := url.Parse(target)
+		if err != nil {
+			return 0
+		}
+		page, _ := strconv.Atoi(u.Query().Get("page"))
+		return page
+	}
+	return 0
+}
+
 func (c *APIClient) createRequestHeaders(accessToken string) Headers {
 	headers := c.baseHeaders.Copy()
 	headers["Authorization"] = "Bearer " + accessToken
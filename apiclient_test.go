@@ -0,0 +1,203 @@
+package gotrue
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/ulbqb/gotrue-go/gotrueapi"
+	"github.com/ulbqb/gotrue-go/internal/testdata"
+)
+
+func TestAPIClient_Admin(t *testing.T) {
+	uid := uuid.New()
+	email := testdata.MockUserEmail()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+testdata.AdminToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"code":401,"msg":"This endpoint requires a Bearer token"}`))
+			return
+		}
+
+		switch r.Method + " " + r.URL.Path {
+		case "GET /admin/users":
+			if r.URL.Query().Get("page") != "2" || r.URL.Query().Get("per_page") != "10" {
+				t.Errorf("AdminListUsers() query = %s", r.URL.RawQuery)
+			}
+			_ = json.NewEncoder(w).Encode(&gotrueapi.UserList{
+				Users: []gotrueapi.User{{ID: uid, Email: email}},
+			})
+
+		case "POST /admin/users":
+			var params gotrueapi.AdminCreateUserParams
+			_ = json.NewDecoder(r.Body).Decode(&params)
+			if !params.EmailConfirm || params.BanDuration != "24h" {
+				t.Errorf("AdminCreateUser() body = %+v", params)
+			}
+			_ = json.NewEncoder(w).Encode(&gotrueapi.User{ID: uid, Email: params.Email})
+
+		case "DELETE /admin/users/" + uid.String():
+			_, _ = w.Write([]byte(`{}`))
+
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewAPIClient(server.URL)
+	client.AppendHeaders(Headers{"Authorization": "Bearer " + testdata.AdminToken})
+
+	t.Run("list users", func(t *testing.T) {
+		list, err := client.AdminListUsers(&gotrueapi.AdminListUsersParams{
+			Page:    2,
+			PerPage: 10,
+		})
+		if err != nil {
+			t.Errorf("AdminListUsers() error = %v", err)
+			return
+		}
+		if len(list.Users) != 1 || list.Users[0].ID != uid {
+			t.Errorf("AdminListUsers() = %v", list.Users)
+		}
+	})
+
+	t.Run("create user", func(t *testing.T) {
+		user, err := client.AdminCreateUser(&gotrueapi.AdminCreateUserParams{
+			Email:        email,
+			Password:     testdata.MockUserPassword(),
+			EmailConfirm: true,
+			BanDuration:  "24h",
+		})
+		if err != nil {
+			t.Errorf("AdminCreateUser() error = %v", err)
+			return
+		}
+		if user.Email != email {
+			t.Errorf("AdminCreateUser() email = %s, want = %s", user.Email, email)
+		}
+	})
+
+	t.Run("delete user", func(t *testing.T) {
+		err := client.AdminDeleteUser(uid)
+		if err != nil {
+			t.Errorf("AdminDeleteUser() error = %v", err)
+		}
+	})
+
+	t.Run("generate signup link without password", func(t *testing.T) {
+		_, err := client.AdminGenerateLink(&gotrueapi.GenerateLinkParams{
+			Type:  gotrueapi.GenerateLinkTypeSignup,
+			Email: email,
+		})
+		if err == nil {
+			t.Errorf("AdminGenerateLink() returns no error")
+		}
+	})
+}
+
+func TestAPIClient_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		wantCode string
+		wantMsg  string
+		wantKind error
+	}{
+		{
+			name:     "error code",
+			status:   http.StatusBadRequest,
+			body:     `{"code":400,"error_code":"invalid_credentials","msg":"Invalid login credentials"}`,
+			wantCode: "invalid_credentials",
+			wantMsg:  "Invalid login credentials",
+			wantKind: ErrInvalidCredentials,
+		},
+		{
+			name:     "string code",
+			status:   http.StatusUnprocessableEntity,
+			body:     `{"code":"email_exists","message":"Email address already registered"}`,
+			wantCode: "email_exists",
+			wantMsg:  "Email address already registered",
+			wantKind: ErrUserAlreadyExists,
+		},
+		{
+			name:     "oauth error",
+			status:   http.StatusBadRequest,
+			body:     `{"error":"invalid_grant","error_description":"Email not confirmed"}`,
+			wantCode: "invalid_grant",
+			wantMsg:  "Email not confirmed",
+			wantKind: ErrEmailNotConfirmed,
+		},
+		{
+			name:     "message without code",
+			status:   http.StatusBadRequest,
+			body:     `{"code":400,"msg":"Invalid Refresh Token: Already Used"}`,
+			wantMsg:  "Invalid Refresh Token: Already Used",
+			wantKind: ErrSessionExpired,
+		},
+		{
+			name:     "signup disabled",
+			status:   http.StatusUnprocessableEntity,
+			body:     `{"code":422,"error_code":"signup_disabled","msg":"Signups not allowed for this instance"}`,
+			wantCode: "signup_disabled",
+			wantMsg:  "Signups not allowed for this instance",
+			wantKind: ErrSignupDisabled,
+		},
+		{
+			name:     "signup disabled without code",
+			status:   http.StatusForbidden,
+			body:     `{"code":403,"msg":"Signups not allowed for this instance"}`,
+			wantMsg:  "Signups not allowed for this instance",
+			wantKind: ErrSignupDisabled,
+		},
+		{
+			name:     "provider disabled",
+			status:   http.StatusUnprocessableEntity,
+			body:     `{"code":422,"error_code":"email_provider_disabled","msg":"Email logins are disabled"}`,
+			wantCode: "email_provider_disabled",
+			wantMsg:  "Email logins are disabled",
+			wantKind: ErrProviderDisabled,
+		},
+		{
+			name:     "not json",
+			status:   http.StatusTooManyRequests,
+			body:     "Too many requests",
+			wantMsg:  "Too many requests",
+			wantKind: ErrRateLimited,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Request-Id", "request-id")
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			_, err := NewAPIClient(server.URL).GetSettings()
+
+			var apiErr *gotrueapi.Error
+			if !errors.As(err, &apiErr) {
+				t.Errorf("GetSettings() error = %v, want *gotrueapi.Error", err)
+				return
+			}
+			if apiErr.Status != tt.status || apiErr.Code != tt.wantCode || apiErr.Message != tt.wantMsg {
+				t.Errorf("GetSettings() error = %+v", apiErr)
+			}
+			if apiErr.RequestID != "request-id" || string(apiErr.Body) != tt.body {
+				t.Errorf("GetSettings() error = %+v, want request id and body", apiErr)
+			}
+			if !errors.Is(err, tt.wantKind) {
+				t.Errorf("errors.Is(%v, %v) = false", err, tt.wantKind)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package gotrue
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/ulbqb/gotrue-go/gotrueapi"
+)
+
+const (
+	// defaultRefreshMargin is how long before expiry the session is
+	// refreshed by default.
+	defaultRefreshMargin = 30 * time.Second
+	// autoRefreshRetryDelay is the delay before the first retry. It doubles
+	// on every following retry.
+	autoRefreshRetryDelay = time.Second
+	// autoRefreshMaxRetryDelay caps the delay between retries.
+	autoRefreshMaxRetryDelay = 30 * time.Second
+)
+
+// StartAutoRefresh enables automatic refresh of the current session. The
+// session is refreshed shortly before its access token expires.
+func (c *Client) StartAutoRefresh() {
+	c.Lock()
+	defer c.Unlock()
+
+	c.autoRefresh = true
+	c.scheduleRefresh()
+}
+
+// StopAutoRefresh disables automatic refresh and cancels a pending refresh.
+func (c *Client) StopAutoRefresh() {
+	c.Lock()
+	defer c.Unlock()
+
+	c.autoRefresh = false
+	c.stopRefreshTimer()
+}
+
+// scheduleRefresh schedules refresh of the current session.
+// scheduleRefresh is not thread safe.
+func (c *Client) scheduleRefresh() {
+	c.stopRefreshTimer()
+
+	session := c.currentSession
+	if !c.autoRefresh || session == nil || len(session.RefreshToken) == 0 {
+		return
+	}
+
+	remaining := time.Until(time.Unix(session.ExpiresAt, 0))
+	delay := remaining - c.refreshMargin
+	// Short-lived tokens are refreshed halfway through their lifetime.
+	if delay < remaining/2 {
+		delay = remaining / 2
+	}
+	c.setRefreshTimer(delay, session, 0)
+}
+
+// setRefreshTimer is not thread safe.
+func (c *Client) setRefreshTimer(delay time.Duration, session *gotrueapi.Session, attempt int) {
+	if delay < 0 {
+		delay = 0
+	}
+	c.refreshTimer = time.AfterFunc(delay, func() {
+		c.autoRefreshSession(session, attempt)
+	})
+}
+
+// stopRefreshTimer is not thread safe.
+func (c *Client) stopRefreshTimer() {
+	if c.refreshTimer != nil {
+		c.refreshTimer.Stop()
+		c.refreshTimer = nil
+	}
+}
+
+func (c *Client) autoRefreshSession(session *gotrueapi.Session, attempt int) {
+	c.Lock()
+	// Auto refresh was stopped or the session was replaced in the meantime.
+	if !c.autoRefresh || c.currentSession != session {
+		c.Unlock()
+		return
+	}
+	call := c.refreshSession(session.RefreshToken, false)
+	c.Unlock()
+
+	_, err := call.wait(context.Background())
+	if err == nil || isRefreshTokenRejected(err) {
+		return
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	c.logf("gotrue: failed to refresh session (attempt %d): %v", attempt+1, err)
+	if !c.autoRefresh || c.currentSession != session {
+		return
+	}
+
+	// Retries until the access token expires, as the refresh token may
+	// still be accepted once the server is back.
+	remaining := time.Until(time.Unix(session.ExpiresAt, 0))
+	if remaining <= 0 {
+		c.logf("gotrue: session expired while refresh kept failing")
+		return
+	}
+
+	delay := autoRefreshRetryDelay
+	for i := 0; i < attempt && delay < autoRefreshMaxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > autoRefreshMaxRetryDelay {
+		delay = autoRefreshMaxRetryDelay
+	}
+	if delay > remaining {
+		delay = remaining
+	}
+	c.setRefreshTimer(delay, session, attempt+1)
+}
+
+// tokenExpiresAt returns when the access token of session expires. The exp
+// claim of the token takes precedence over expires_at, and expires_at over
+// expires_in counted from receivedAt.
+func tokenExpiresAt(session *gotrueapi.Session, receivedAt time.Time) time.Time {
+	claims, err := gotrueapi.DecodeClaims(session.Token)
+	if err == nil && claims.ExpiresAt != nil {
+		return claims.ExpiresAt.Time
+	}
+	if session.ExpiresAt > 0 {
+		return time.Unix(session.ExpiresAt, 0)
+	}
+	return receivedAt.Add(time.Duration(session.ExpiresIn) * time.Second)
+}
+
+// fillExpiresAt sets ExpiresAt of session received at receivedAt, unless it
+// is set already.
+func fillExpiresAt(session *gotrueapi.Session, receivedAt time.Time) {
+	if session.ExpiresAt == 0 && len(session.Token) > 0 {
+		session.ExpiresAt = tokenExpiresAt(session, receivedAt).Unix()
+	}
+}
+
+// isRefreshTokenRejected reports whether err means the refresh token is no
+// longer valid, as opposed to a transient failure.
+func isRefreshTokenRejected(err error) bool {
+	var apiErr *gotrueapi.Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	if errors.Is(err, ErrSessionExpired) {
+		return true
+	}
+	return apiErr.Status >= 400 && apiErr.Status < 500 &&
+		!errors.Is(err, ErrRateLimited)
+}
@@ -0,0 +1,139 @@
+package gotrue
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ulbqb/gotrue-go/gotrueapi"
+)
+
+func TestClient_AutoRefresh(t *testing.T) {
+	t.Run("refreshes session before expiry", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewEncoder(w).Encode(&gotrueapi.Session{
+				Token:        "refreshed-access-token",
+				TokenType:    "bearer",
+				ExpiresIn:    3600,
+				RefreshToken: "refreshed-refresh-token",
+			})
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL)
+		defer client.StopAutoRefresh()
+
+		var ch = make(chan struct{}, 1)
+		unsubscribe := client.Subscribe(TokenRefreshedEvent, func() {
+			ch <- struct{}{}
+		})
+		defer unsubscribe()
+
+		client.Lock()
+		client.saveSession(&gotrueapi.Session{
+			Token:        "access-token",
+			ExpiresIn:    1,
+			RefreshToken: "refresh-token",
+		})
+		client.Unlock()
+
+		select {
+		case <-ch:
+		case <-time.After(5 * time.Second):
+			t.Errorf("auto refresh does not fire token refreshed event")
+			return
+		}
+
+		if s := client.Session(); s == nil || s.RefreshToken != "refreshed-refresh-token" {
+			t.Errorf("auto refresh does not save refreshed session; got = %v", s)
+		}
+	})
+
+	t.Run("signs out when refresh token is rejected", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_grant","error_description":"Invalid Refresh Token"}`))
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL)
+		defer client.StopAutoRefresh()
+
+		var ch = make(chan struct{}, 1)
+		unsubscribe := client.Subscribe(SignedOutEvent, func() {
+			ch <- struct{}{}
+		})
+		defer unsubscribe()
+
+		client.Lock()
+		client.saveSession(&gotrueapi.Session{
+			Token:        "access-token",
+			ExpiresIn:    1,
+			RefreshToken: "refresh-token",
+		})
+		client.Unlock()
+
+		select {
+		case <-ch:
+		case <-time.After(5 * time.Second):
+			t.Errorf("auto refresh does not fire signed out event")
+			return
+		}
+
+		if s := client.Session(); s != nil {
+			t.Errorf("auto refresh keeps rejected session; got = %v", s)
+		}
+	})
+
+	t.Run("stopped auto refresh does not refresh", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("unexpected request %s", r.URL)
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL)
+		client.StopAutoRefresh()
+
+		client.Lock()
+		client.saveSession(&gotrueapi.Session{
+			Token:        "access-token",
+			ExpiresIn:    0,
+			RefreshToken: "refresh-token",
+		})
+		client.Unlock()
+
+		time.Sleep(time.Second / 10)
+	})
+
+	t.Run("keeps retrying until expiry", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL)
+		defer client.StopAutoRefresh()
+
+		session := &gotrueapi.Session{
+			Token:        "access-token",
+			ExpiresAt:    time.Now().Add(time.Hour).Unix(),
+			RefreshToken: "refresh-token",
+		}
+		client.Lock()
+		client.saveSession(session)
+		client.stopRefreshTimer()
+		client.Unlock()
+
+		// Well past the point where backoff reaches its cap.
+		client.autoRefreshSession(session, 20)
+
+		client.Lock()
+		scheduled := client.refreshTimer != nil
+		client.Unlock()
+		if !scheduled {
+			t.Errorf("auto refresh gives up before the session expires")
+		}
+	})
+}
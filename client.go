@@ -1,8 +1,10 @@
 package gotrue
 
 import (
+	"context"
 	"strconv"
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -17,19 +19,59 @@ type Client struct {
 	currentSession *gotrueapi.Session
 	currentUser    *gotrueapi.User
 
+	flowType        FlowType
+	verifierStorage SessionStorage
+	storage         SessionStorage
+	storageKey      string
+
+	settings          settingsCache
+	settingsPreflight bool
+
+	logger Logger
+
+	autoRefresh   bool
+	refreshMargin time.Duration
+	refreshTimer  *time.Timer
+	refreshCall   *refreshCall
+
 	eventChannel *EventChannel
 }
 
-func NewClient(url string) *Client {
-	return &Client{
-		api:          NewAPIClient(url),
-		eventChannel: NewEventChannel(),
+// NewClient creates a client. Automatic refresh of the session is enabled
+// unless WithAutoRefresh(false) is given.
+func NewClient(url string, opts ...Option) *Client {
+	o := newOptions(opts)
+
+	c := &Client{
+		api:               newAPIClient(url, o),
+		autoRefresh:       o.autoRefresh,
+		refreshMargin:     o.refreshMargin,
+		settingsPreflight: o.settingsPreflight,
+		flowType:          o.flowType,
+		storage:           o.storage,
+		storageKey:        o.storageKey,
+		logger:            o.logger,
+		eventChannel:      NewEventChannel(),
 	}
+	if len(c.storageKey) == 0 {
+		c.storageKey = defaultStorageKey(url)
+	}
+	c.verifierStorage = c.storage
+	if c.verifierStorage == nil {
+		c.verifierStorage = NewMemoryStorage()
+	}
+
+	return c
 }
 
 // SignUpWithEmail creates new account with email address.
 func (c *Client) SignUpWithEmail(email, password string, data interface{}) (*gotrueapi.Session, error) {
-	return c.signUpWithPassword(&gotrueapi.SignUpParams{
+	return c.SignUpWithEmailWithContext(context.Background(), email, password, data)
+}
+
+// SignUpWithEmailWithContext is like SignUpWithEmail but uses ctx for the requests.
+func (c *Client) SignUpWithEmailWithContext(ctx context.Context, email, password string, data interface{}) (*gotrueapi.Session, error) {
+	return c.signUpWithPassword(ctx, &gotrueapi.SignUpParams{
 		Email:    email,
 		Password: password,
 		Data:     data,
@@ -38,7 +80,12 @@ func (c *Client) SignUpWithEmail(email, password string, data interface{}) (*got
 
 // SignUpWithPhone creates new account with phone number.
 func (c *Client) SignUpWithPhone(phone, password string, data interface{}) (*gotrueapi.Session, error) {
-	return c.signUpWithPassword(&gotrueapi.SignUpParams{
+	return c.SignUpWithPhoneWithContext(context.Background(), phone, password, data)
+}
+
+// SignUpWithPhoneWithContext is like SignUpWithPhone but uses ctx for the requests.
+func (c *Client) SignUpWithPhoneWithContext(ctx context.Context, phone, password string, data interface{}) (*gotrueapi.Session, error) {
+	return c.signUpWithPassword(ctx, &gotrueapi.SignUpParams{
 		Phone:    phone,
 		Password: password,
 		Data:     data,
@@ -47,7 +94,12 @@ func (c *Client) SignUpWithPhone(phone, password string, data interface{}) (*got
 
 // SignInWithEmail issues access token with user email and password.
 func (c *Client) SignInWithEmail(email, password string) (*gotrueapi.Session, error) {
-	return c.signInWithPasswordGrant(&gotrueapi.TokenWithPasswordGrantParams{
+	return c.SignInWithEmailWithContext(context.Background(), email, password)
+}
+
+// SignInWithEmailWithContext is like SignInWithEmail but uses ctx for the requests.
+func (c *Client) SignInWithEmailWithContext(ctx context.Context, email, password string) (*gotrueapi.Session, error) {
+	return c.signInWithPasswordGrant(ctx, &gotrueapi.TokenWithPasswordGrantParams{
 		Email:    email,
 		Password: password,
 	})
@@ -55,7 +107,12 @@ func (c *Client) SignInWithEmail(email, password string) (*gotrueapi.Session, er
 
 // SignInWithPhone issues access token with user's phone number and password.
 func (c *Client) SignInWithPhone(phone, password string) (*gotrueapi.Session, error) {
-	return c.signInWithPasswordGrant(&gotrueapi.TokenWithPasswordGrantParams{
+	return c.SignInWithPhoneWithContext(context.Background(), phone, password)
+}
+
+// SignInWithPhoneWithContext is like SignInWithPhone but uses ctx for the requests.
+func (c *Client) SignInWithPhoneWithContext(ctx context.Context, phone, password string) (*gotrueapi.Session, error) {
+	return c.signInWithPasswordGrant(ctx, &gotrueapi.TokenWithPasswordGrantParams{
 		Phone:    phone,
 		Password: password,
 	})
@@ -63,22 +120,195 @@ func (c *Client) SignInWithPhone(phone, password string) (*gotrueapi.Session, er
 
 // SignInWithMagicLink sends "magic link" email to user.
 func (c *Client) SignInWithMagicLink(params *gotrueapi.MagicLinkParams) error {
-	return c.api.SendMagicLinkEmail(params)
+	return c.SignInWithMagicLinkWithContext(context.Background(), params)
+}
+
+// SignInWithMagicLinkWithContext is like SignInWithMagicLink but uses ctx for the requests.
+func (c *Client) SignInWithMagicLinkWithContext(ctx context.Context, params *gotrueapi.MagicLinkParams) error {
+	challenge, err := c.codeChallenge()
+	if err != nil {
+		return err
+	}
+	if len(challenge) > 0 {
+		p := *params
+		p.CodeChallenge = challenge
+		p.CodeChallengeMethod = gotrueapi.CodeChallengeMethodS256
+		params = &p
+	}
+
+	return c.api.SendMagicLinkEmailWithContext(ctx, params)
 }
 
 // SignInWithOTP sends OTP to user's phone.
 func (c *Client) SignInWithOTP(params *gotrueapi.OTPParams) error {
-	return c.api.SendMobileOTP(params)
+	return c.SignInWithOTPWithContext(context.Background(), params)
+}
+
+// SignInWithOTPWithContext is like SignInWithOTP but uses ctx for the requests.
+func (c *Client) SignInWithOTPWithContext(ctx context.Context, params *gotrueapi.OTPParams) error {
+	if err := c.checkProvider(ctx, params.Email, params.Phone, false); err != nil {
+		return err
+	}
+
+	challenge, err := c.codeChallenge()
+	if err != nil {
+		return err
+	}
+	if len(challenge) > 0 {
+		p := *params
+		p.CodeChallenge = challenge
+		p.CodeChallengeMethod = gotrueapi.CodeChallengeMethodS256
+		params = &p
+	}
+
+	return c.api.SendMobileOTPWithContext(ctx, params)
 }
 
-// SignInWithProvider returns sign in url for provider.
+// VerifyOTP verifies a token sent by SMS or email and signs the user in.
+func (c *Client) VerifyOTP(params *gotrueapi.VerifyParams) (*gotrueapi.Session, error) {
+	return c.VerifyOTPWithContext(context.Background(), params)
+}
+
+// VerifyOTPWithContext is like VerifyOTP but uses ctx for the requests.
+func (c *Client) VerifyOTPWithContext(ctx context.Context, params *gotrueapi.VerifyParams) (*gotrueapi.Session, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	session, err := c.api.VerifyWithContext(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(session.Token) > 0 {
+		c.saveSession(session)
+		c.eventChannel.Publish(SignedInEvent)
+		if params.Type == gotrueapi.VerifyTypeRecovery {
+			c.eventChannel.Publish(PasswordRecoveryEvent)
+		}
+	}
+
+	return session, nil
+}
+
+// SignInAnonymously signs in as a new anonymous user. The user can be
+// converted to a permanent one by adding an email or phone and a password with
+// UpdateUser, keeping its ID.
+func (c *Client) SignInAnonymously(data map[string]interface{}, captchaToken string) (*gotrueapi.Session, error) {
+	return c.SignInAnonymouslyWithContext(context.Background(), data, captchaToken)
+}
+
+// SignInAnonymouslyWithContext is like SignInAnonymously but uses ctx for the requests.
+func (c *Client) SignInAnonymouslyWithContext(ctx context.Context, data map[string]interface{}, captchaToken string) (*gotrueapi.Session, error) {
+	if err := c.checkProvider(ctx, "", "", true); err != nil {
+		return nil, err
+	}
+	if err := c.checkExternalProvider(ctx, "anonymous_users"); err != nil {
+		return nil, err
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	params := &gotrueapi.AnonymousSignUpParams{
+		Security: gotrueapi.Security{HCaptchaToken: captchaToken},
+	}
+	if data != nil {
+		params.Data = data
+	}
+	session, err := c.api.SignUpAnonymouslyWithContext(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	c.saveSession(session)
+	c.eventChannel.Publish(SignedInEvent)
+
+	return session, nil
+}
+
+// SignInWithIDToken signs in with an ID token issued to a native app, such as
+// by Sign in with Apple or Google Sign-In.
+func (c *Client) SignInWithIDToken(params *gotrueapi.TokenWithIDTokenGrantParams) (*gotrueapi.Session, error) {
+	return c.SignInWithIDTokenWithContext(context.Background(), params)
+}
+
+// SignInWithIDTokenWithContext is like SignInWithIDToken but uses ctx for the requests.
+func (c *Client) SignInWithIDTokenWithContext(ctx context.Context, params *gotrueapi.TokenWithIDTokenGrantParams) (*gotrueapi.Session, error) {
+	if err := c.checkExternalProvider(ctx, params.Provider); err != nil {
+		return nil, err
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	session, err := c.api.IssueTokenWithIDTokenWithContext(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	c.saveSession(session)
+	c.eventChannel.Publish(SignedInEvent)
+
+	return session, nil
+}
+
+// SignInWithSSO returns the URL to send the user to for signing in with the
+// SSO identity provider of params. With the PKCE flow, the redirect has to be
+// completed with ExchangeCodeForSession.
+func (c *Client) SignInWithSSO(params *gotrueapi.SSOParams) (string, error) {
+	return c.SignInWithSSOWithContext(context.Background(), params)
+}
+
+// SignInWithSSOWithContext is like SignInWithSSO but uses ctx for the requests.
+func (c *Client) SignInWithSSOWithContext(ctx context.Context, params *gotrueapi.SSOParams) (string, error) {
+	if settings := c.loadSettings(ctx); settings != nil && settings.SAMLEnabled != nil && !*settings.SAMLEnabled {
+		return "", errors.Wrap(ErrProviderDisabled, "sso")
+	}
+
+	challenge, err := c.codeChallenge()
+	if err != nil {
+		return "", err
+	}
+	if len(challenge) > 0 {
+		p := *params
+		p.CodeChallenge = challenge
+		p.CodeChallengeMethod = gotrueapi.CodeChallengeMethodS256
+		params = &p
+	}
+
+	return c.api.SSOWithContext(ctx, params)
+}
+
+// SignInWithProvider returns sign in url for provider. With the PKCE flow,
+// the redirect has to be completed with ExchangeCodeForSession. It returns an
+// empty url if the code verifier cannot be stored; use SignInWithProviderURL
+// to get the error.
 func (c *Client) SignInWithProvider(provider Provider, redirectTo, scopes string) string {
-	return c.api.GetProviderSignInURL(provider, redirectTo, scopes)
+	u, err := c.SignInWithProviderURL(provider, redirectTo, scopes)
+	if err != nil {
+		c.logf("gotrue: %v", err)
+	}
+	return u
+}
+
+// SignInWithProviderURL is like SignInWithProvider but returns the error of
+// storing the code verifier.
+func (c *Client) SignInWithProviderURL(provider Provider, redirectTo, scopes string) (string, error) {
+	challenge, err := c.codeChallenge()
+	if err != nil {
+		return "", err
+	}
+	return c.api.GetProviderSignInURLWithCodeChallenge(provider, redirectTo, scopes, challenge), nil
 }
 
 // SignOut destroys current session. Note that revoked token is still be valid
 // for stateless services.
 func (c *Client) SignOut() error {
+	return c.SignOutWithContext(context.Background())
+}
+
+// SignOutWithContext is like SignOut but uses ctx for the requests.
+func (c *Client) SignOutWithContext(ctx context.Context) error {
 	c.Lock()
 	defer c.Unlock()
 
@@ -94,28 +324,57 @@ func (c *Client) SignOut() error {
 		return nil
 	}
 
-	return c.api.SignOut(token)
+	return c.api.SignOutWithContext(ctx, token)
 }
 
 // ResetPasswordForEmail sends a recover email to the user.
 func (c *Client) ResetPasswordForEmail(params *gotrueapi.RecoverParams) error {
-	return c.api.ResetPasswordForEmail(params)
+	return c.ResetPasswordForEmailWithContext(context.Background(), params)
+}
+
+// ResetPasswordForEmailWithContext is like ResetPasswordForEmail but uses ctx for the requests.
+func (c *Client) ResetPasswordForEmailWithContext(ctx context.Context, params *gotrueapi.RecoverParams) error {
+	challenge, err := c.codeChallenge()
+	if err != nil {
+		return err
+	}
+	if len(challenge) > 0 {
+		p := *params
+		p.CodeChallenge = challenge
+		p.CodeChallengeMethod = gotrueapi.CodeChallengeMethodS256
+		params = &p
+	}
+
+	return c.api.ResetPasswordForEmailWithContext(ctx, params)
 }
 
 // Session returns copy of current session. returned session is only vaild until
-// next refresh.
+// next refresh. A session about to expire is refreshed first, see
+// WithRefreshMargin. If the refresh fails, the current session is returned
+// even if its access token has expired; nil means not signed in.
 func (c *Client) Session() *gotrueapi.Session {
-	c.RLock()
-	defer c.RUnlock()
-	if c.currentSession == nil {
-		return nil
+	session, err := c.SessionWithContext(context.Background())
+	if err != nil {
+		c.logf("gotrue: %v", err)
+		return c.sessionCopy()
 	}
-	s := *c.currentSession
-	return &s
+	return session
+}
+
+// SessionWithContext is like Session but uses ctx for the refresh, and
+// returns an error instead of a session whose access token has expired.
+func (c *Client) SessionWithContext(ctx context.Context) (*gotrueapi.Session, error) {
+	return c.freshSession(ctx)
 }
 
-// GetSessionFromURL parses url and returns generated session.
+// GetSessionFromURL parses url and returns generated session. A url with the
+// code of the PKCE flow is exchanged for a session, which is always stored.
 func (c *Client) GetSessionFromURL(url string, storeSession bool) (*gotrueapi.Session, error) {
+	return c.GetSessionFromURLWithContext(context.Background(), url, storeSession)
+}
+
+// GetSessionFromURLWithContext is like GetSessionFromURL but uses ctx for the requests.
+func (c *Client) GetSessionFromURLWithContext(ctx context.Context, url string, storeSession bool) (*gotrueapi.Session, error) {
 	values, err := getParametersFromURI(url)
 	if err != nil {
 		return nil, err
@@ -125,6 +384,10 @@ func (c *Client) GetSessionFromURL(url string, storeSession bool) (*gotrueapi.Se
 		return nil, errors.New(v)
 	}
 
+	if code := values.Get("code"); len(code) > 0 && len(values.Get("access_token")) == 0 {
+		return c.ExchangeCodeForSessionWithContext(ctx, code)
+	}
+
 	accessToken := values.Get("access_token")
 	if len(accessToken) == 0 {
 		return nil, errors.New("api: no access_token was provided")
@@ -135,6 +398,14 @@ func (c *Client) GetSessionFromURL(url string, storeSession bool) (*gotrueapi.Se
 		return nil, errors.Wrap(err, "api: expires_in is invalid")
 	}
 
+	var expiresAt int64
+	if v := values.Get("expires_at"); len(v) > 0 {
+		expiresAt, err = strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, errors.Wrap(err, "api: expires_at is invalid")
+		}
+	}
+
 	refreshToken := values.Get("refresh_token")
 	if len(refreshToken) == 0 {
 		return nil, errors.New("api: no refresh_token was provided")
@@ -145,7 +416,7 @@ func (c *Client) GetSessionFromURL(url string, storeSession bool) (*gotrueapi.Se
 		return nil, errors.New("api: no token_type was provided")
 	}
 
-	user, err := c.api.GetUser(accessToken)
+	user, err := c.api.GetUserWithContext(ctx, accessToken)
 	if err != nil {
 		return nil, err
 	}
@@ -154,16 +425,20 @@ func (c *Client) GetSessionFromURL(url string, storeSession bool) (*gotrueapi.Se
 		Token:        accessToken,
 		TokenType:    tokenType,
 		ExpiresIn:    int(expiresIn),
+		ExpiresAt:    expiresAt,
 		RefreshToken: refreshToken,
 		User:         user,
 	}
+	fillExpiresAt(session, time.Now())
 
 	if storeSession {
+		c.Lock()
 		c.saveSession(session)
 		c.eventChannel.Publish(SignedInEvent)
 		if values.Get("type") == "recovery" {
 			c.eventChannel.Publish(PasswordRecoveryEvent)
 		}
+		c.Unlock()
 	}
 
 	return session, nil
@@ -179,8 +454,66 @@ func (c *Client) User() *gotrueapi.User {
 	return &u
 }
 
+// RefreshSession refreshes current session and returns a copy of the new one.
+// Concurrent calls share a single refresh request.
+func (c *Client) RefreshSession() (*gotrueapi.Session, error) {
+	return c.RefreshSessionWithContext(context.Background())
+}
+
+// RefreshSessionWithContext is like RefreshSession but uses ctx for the requests.
+func (c *Client) RefreshSessionWithContext(ctx context.Context) (*gotrueapi.Session, error) {
+	c.Lock()
+	if c.currentSession == nil || len(c.currentSession.RefreshToken) == 0 {
+		c.Unlock()
+		return nil, errors.New("not signed in")
+	}
+	call := c.refreshSession(c.currentSession.RefreshToken, false)
+	c.Unlock()
+
+	return call.wait(ctx)
+}
+
+// SetSession issues a new session with provided refresh token, makes it
+// current and returns a copy of it.
+func (c *Client) SetSession(refreshToken string) (*gotrueapi.Session, error) {
+	return c.SetSessionWithContext(context.Background(), refreshToken)
+}
+
+// SetSessionWithContext is like SetSession but uses ctx for the requests.
+func (c *Client) SetSessionWithContext(ctx context.Context, refreshToken string) (*gotrueapi.Session, error) {
+	if len(refreshToken) == 0 {
+		return nil, errors.New("refresh token is required")
+	}
+
+	c.Lock()
+	call := c.refreshSession(refreshToken, true)
+	c.Unlock()
+
+	return call.wait(ctx)
+}
+
+// Claims decodes the claims of the current access token. The token was
+// issued to this client by GoTrue, so its signature is not verified.
+func (c *Client) Claims() (*gotrueapi.Claims, error) {
+	c.RLock()
+	defer c.RUnlock()
+
+	if c.currentSession == nil || len(c.currentSession.Token) == 0 {
+		return nil, errors.New("not signed in")
+	}
+
+	return gotrueapi.DecodeClaims(c.currentSession.Token)
+}
+
 // UpdateUser updates current user with provided params and returns updated user.
+// Changing the password may return ErrReauthenticationNeeded, see
+// Reauthenticate.
 func (c *Client) UpdateUser(params *gotrueapi.PutUserParams) (*gotrueapi.User, error) {
+	return c.UpdateUserWithContext(context.Background(), params)
+}
+
+// UpdateUserWithContext is like UpdateUser but uses ctx for the requests.
+func (c *Client) UpdateUserWithContext(ctx context.Context, params *gotrueapi.PutUserParams) (*gotrueapi.User, error) {
 	c.Lock()
 	defer c.Unlock()
 
@@ -188,28 +521,131 @@ func (c *Client) UpdateUser(params *gotrueapi.PutUserParams) (*gotrueapi.User, e
 		return nil, errors.New("not signed in")
 	}
 
-	user, err := c.api.UpdateUser(c.currentSession.Token, params)
+	user, err := c.api.UpdateUserWithContext(ctx, c.currentSession.Token, params)
 	if err != nil {
 		return nil, err
 	}
 
-	c.currentSession.User = user
+	converted := c.currentUser != nil && c.currentUser.IsAnonymous && !user.IsAnonymous
+	c.setUser(user)
 	c.eventChannel.Publish(UserUpdatedEvent)
 
+	// The access token keeps the is_anonymous claim until it is refreshed.
+	if converted {
+		c.refreshSession(c.currentSession.RefreshToken, false)
+	}
+
 	return user, nil
 }
 
+// Reauthenticate sends a nonce to the email or phone of the signed in user.
+// Set it as the Nonce of UpdateUser to change the password when the server
+// returns ErrReauthenticationNeeded.
+func (c *Client) Reauthenticate() error {
+	return c.ReauthenticateWithContext(context.Background())
+}
+
+// ReauthenticateWithContext is like Reauthenticate but uses ctx for the requests.
+func (c *Client) ReauthenticateWithContext(ctx context.Context) error {
+	token, err := c.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	return c.api.ReauthenticateWithContext(ctx, token)
+}
+
+// accessToken returns the access token of the current session, refreshed if
+// it is about to expire.
+func (c *Client) accessToken(ctx context.Context) (string, error) {
+	session, err := c.freshSession(ctx)
+	if err != nil {
+		return "", err
+	}
+	if session == nil || len(session.Token) == 0 {
+		return "", errors.New("not signed in")
+	}
+	return session.Token, nil
+}
+
+// freshSession returns a copy of the current session. A session whose access
+// token expires within the refresh margin is refreshed first. The current
+// session is returned if the refresh fails while its token is still valid.
+func (c *Client) freshSession(ctx context.Context) (*gotrueapi.Session, error) {
+	c.RLock()
+	fresh := !c.needsRefresh()
+	c.RUnlock()
+	if fresh {
+		return c.sessionCopy(), nil
+	}
+
+	c.Lock()
+	// Another caller may have refreshed the session meanwhile.
+	if !c.needsRefresh() {
+		c.Unlock()
+		return c.sessionCopy(), nil
+	}
+	session := c.currentSession
+	call := c.refreshSession(session.RefreshToken, false)
+	c.Unlock()
+
+	if _, err := call.wait(ctx); err != nil {
+		if session.IsExpired(0) {
+			return nil, errors.Wrap(err, "failed to refresh expired session")
+		}
+		c.logf("gotrue: failed to refresh session: %v", err)
+	}
+
+	return c.sessionCopy(), nil
+}
+
+// needsRefresh reports whether the current session has to be refreshed
+// before it is used. needsRefresh is not thread safe.
+func (c *Client) needsRefresh() bool {
+	session := c.currentSession
+	return session != nil && len(session.RefreshToken) > 0 && session.IsExpired(c.refreshMargin)
+}
+
+// sessionCopy returns a copy of the current session, or nil if there is none.
+func (c *Client) sessionCopy() *gotrueapi.Session {
+	c.RLock()
+	defer c.RUnlock()
+	if c.currentSession == nil {
+		return nil
+	}
+	s := *c.currentSession
+	return &s
+}
+
 func (c *Client) Subscribe(event AuthChangeEvent, fn func()) func() {
 	return c.eventChannel.Subscribe(event, fn)
 }
 
-func (c *Client) signUpWithPassword(params *gotrueapi.SignUpParams) (*gotrueapi.Session, error) {
+func (c *Client) signUpWithPassword(ctx context.Context, params *gotrueapi.SignUpParams) (*gotrueapi.Session, error) {
+	if err := c.checkProvider(ctx, params.Email, params.Phone, true); err != nil {
+		return nil, err
+	}
+
 	c.Lock()
 	defer c.Unlock()
 
+	// The confirmation link of an email sign up redirects with a code.
+	if len(params.Email) > 0 {
+		challenge, err := c.codeChallenge()
+		if err != nil {
+			return nil, err
+		}
+		if len(challenge) > 0 {
+			p := *params
+			p.CodeChallenge = challenge
+			p.CodeChallengeMethod = gotrueapi.CodeChallengeMethodS256
+			params = &p
+		}
+	}
+
 	c.destroySession()
 
-	session, err := c.api.SignUp(params)
+	session, err := c.api.SignUpWithContext(ctx, params)
 	if err != nil {
 		return nil, err
 	}
@@ -227,13 +663,17 @@ func (c *Client) signUpWithPassword(params *gotrueapi.SignUpParams) (*gotrueapi.
 	return session, nil
 }
 
-func (c *Client) signInWithPasswordGrant(params *gotrueapi.TokenWithPasswordGrantParams) (*gotrueapi.Session, error) {
+func (c *Client) signInWithPasswordGrant(ctx context.Context, params *gotrueapi.TokenWithPasswordGrantParams) (*gotrueapi.Session, error) {
+	if err := c.checkProvider(ctx, params.Email, params.Phone, false); err != nil {
+		return nil, err
+	}
+
 	c.Lock()
 	defer c.Unlock()
 
 	c.destroySession()
 
-	session, err := c.api.IssueTokenWithPassword(params)
+	session, err := c.api.IssueTokenWithPasswordWithContext(ctx, params)
 	if err != nil {
 		return nil, err
 	}
@@ -246,42 +686,112 @@ func (c *Client) signInWithPasswordGrant(params *gotrueapi.TokenWithPasswordGran
 	return session, err
 }
 
-// refreshSession refreshes session.
-// refreshSession is not thread safe.
-func (c *Client) refreshSession() (*gotrueapi.Session, error) {
-	if c.currentSession == nil {
-		return nil, errors.New("no session")
+// refreshCall is an in-flight refresh of a session. Concurrent refreshes with
+// the same refresh token share one refreshCall.
+type refreshCall struct {
+	refreshToken string
+	// store makes the refreshed session current even if the client holds
+	// another session.
+	store bool
+
+	done    chan struct{}
+	session *gotrueapi.Session
+	err     error
+}
+
+// wait returns a copy of the refreshed session, so that callers cannot change
+// the current session.
+func (call *refreshCall) wait(ctx context.Context) (*gotrueapi.Session, error) {
+	select {
+	case <-call.done:
+		if call.err != nil {
+			return nil, call.err
+		}
+		s := *call.session
+		return &s, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
 	}
-	refreshToken := c.currentSession.RefreshToken
-	return c.refreshSessionWithToken(refreshToken)
 }
 
-// refreshSessionWithToken refreshes session with provided refresh token.
-// refreshSessionWithToken is not thread safe.
-func (c *Client) refreshSessionWithToken(refreshToken string) (*gotrueapi.Session, error) {
-	session, err := c.api.IssueTokenWithRefreshToken(&gotrueapi.TokenWithRefreshTokenGrantParams{
-		RefreshToken: refreshToken,
-	})
-	if err != nil {
-		return nil, err
+// refreshSession starts refresh with provided refresh token or joins the one
+// in flight. refreshSession is not thread safe.
+func (c *Client) refreshSession(refreshToken string, store bool) *refreshCall {
+	if call := c.refreshCall; call != nil && call.refreshToken == refreshToken {
+		call.store = call.store || store
+		return call
 	}
 
-	c.saveSession(session)
-	c.eventChannel.Publish(TokenRefreshedEvent)
-	c.eventChannel.Publish(SignedInEvent)
+	call := &refreshCall{
+		refreshToken: refreshToken,
+		store:        store,
+		done:         make(chan struct{}),
+	}
+	c.refreshCall = call
+
+	// The request is shared by all callers, so it is not bound to any of
+	// their contexts.
+	go func() {
+		session, err := c.api.IssueTokenWithRefreshToken(&gotrueapi.TokenWithRefreshTokenGrantParams{
+			RefreshToken: refreshToken,
+		})
+
+		c.Lock()
+		if c.refreshCall == call {
+			c.refreshCall = nil
+		}
+		current := c.currentSession != nil && c.currentSession.RefreshToken == refreshToken
+		switch {
+		case err == nil && (current || call.store):
+			c.saveSession(session)
+			c.eventChannel.Publish(TokenRefreshedEvent)
+			c.eventChannel.Publish(SignedInEvent)
+		case err != nil && current && isRefreshTokenRejected(err):
+			c.destroySession()
+			c.eventChannel.Publish(SignedOutEvent)
+		}
+		call.session, call.err = session, err
+		c.Unlock()
 
-	return session, nil
+		close(call.done)
+	}()
+
+	return call
 }
 
-// saveSession saves the token. saveSession does not fire any events and
-// not thread safe.
+// saveSession saves the token and schedules its refresh. ExpiresAt of session
+// is filled in if the server did not set it. saveSession does not fire any
+// events and not thread safe.
 func (c *Client) saveSession(session *gotrueapi.Session) {
+	fillExpiresAt(session, time.Now())
 	c.currentSession = session
 	c.currentUser = session.User
+	c.persistSession()
+	c.scheduleRefresh()
+}
+
+// setUser replaces the user of the current session. setUser is not thread
+// safe.
+func (c *Client) setUser(user *gotrueapi.User) {
+	c.currentSession.User = user
+	c.currentUser = user
+	c.persistSession()
 }
 
 // destroySession destroys the session. destroySession is not thread safe.
 func (c *Client) destroySession() {
 	c.currentSession = nil
 	c.currentUser = nil
+	c.stopRefreshTimer()
+	if c.storage != nil {
+		if err := c.storage.Remove(c.storageKey); err != nil {
+			c.logf("gotrue: failed to remove stored session: %v", err)
+		}
+	}
+}
+
+func (c *Client) logf(format string, v ...interface{}) {
+	if c.logger != nil {
+		c.logger.Printf(format, v...)
+	}
 }
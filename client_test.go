@@ -1,10 +1,20 @@
 package gotrue
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+
 	"github.com/ulbqb/gotrue-go/gotrueapi"
 	"github.com/ulbqb/gotrue-go/internal/testdata"
 )
@@ -19,7 +29,7 @@ func TestClient_signUpWithPassword(t *testing.T) {
 	t.Run("without auto confirm", func(t *testing.T) {
 		t.Run("sign up with email", func(t *testing.T) {
 			email := testdata.MockUserEmail()
-			session, err := authClient.signUpWithPassword(&gotrueapi.SignUpParams{
+			session, err := authClient.signUpWithPassword(context.Background(), &gotrueapi.SignUpParams{
 				Email:    email,
 				Password: testdata.MockUserPassword(),
 			})
@@ -37,7 +47,7 @@ func TestClient_signUpWithPassword(t *testing.T) {
 
 	t.Run("with auto confirm", func(t *testing.T) {
 		t.Run("sign up with email", func(t *testing.T) {
-			session, err := authClientWithAutoConfirm.signUpWithPassword(&gotrueapi.SignUpParams{
+			session, err := authClientWithAutoConfirm.signUpWithPassword(context.Background(), &gotrueapi.SignUpParams{
 				Email:    testdata.MockUserEmail(),
 				Password: testdata.MockUserPassword(),
 			})
@@ -52,7 +62,7 @@ func TestClient_signUpWithPassword(t *testing.T) {
 		})
 
 		t.Run("sign up with phone", func(t *testing.T) {
-			session, err := authClientWithAutoConfirm.signUpWithPassword(&gotrueapi.SignUpParams{
+			session, err := authClientWithAutoConfirm.signUpWithPassword(context.Background(), &gotrueapi.SignUpParams{
 				Phone:    testdata.MockUserPhone(),
 				Password: testdata.MockUserPassword(),
 			})
@@ -74,7 +84,7 @@ func TestClient_signUpWithPassword(t *testing.T) {
 			})
 			defer unsubscribe()
 
-			_, err := authClientWithAutoConfirm.signUpWithPassword(&gotrueapi.SignUpParams{
+			_, err := authClientWithAutoConfirm.signUpWithPassword(context.Background(), &gotrueapi.SignUpParams{
 				Email:    testdata.MockUserEmail(),
 				Password: testdata.MockUserPassword(),
 			})
@@ -95,7 +105,7 @@ func TestClient_signUpWithPassword(t *testing.T) {
 	})
 
 	t.Run("signup disabled", func(t *testing.T) {
-		_, err := authClientWithoutSignUp.signUpWithPassword(&gotrueapi.SignUpParams{
+		_, err := authClientWithoutSignUp.signUpWithPassword(context.Background(), &gotrueapi.SignUpParams{
 			Email:    testdata.MockUserEmail(),
 			Password: testdata.MockUserPassword(),
 		})
@@ -221,3 +231,653 @@ func TestClient_SignOut(t *testing.T) {
 		}
 	})
 }
+
+func TestClient_RefreshSession(t *testing.T) {
+	t.Run("concurrent refreshes share one request", func(t *testing.T) {
+		var requests int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			time.Sleep(time.Second / 10)
+			_ = json.NewEncoder(w).Encode(&gotrueapi.Session{
+				Token:        "refreshed-access-token",
+				TokenType:    "bearer",
+				ExpiresIn:    3600,
+				RefreshToken: "refreshed-refresh-token",
+			})
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL)
+		client.StopAutoRefresh()
+
+		client.Lock()
+		client.saveSession(&gotrueapi.Session{
+			Token:        "access-token",
+			ExpiresIn:    3600,
+			RefreshToken: "refresh-token",
+		})
+		client.Unlock()
+
+		const n = 50
+		var (
+			wg       sync.WaitGroup
+			sessions = make([]*gotrueapi.Session, n)
+		)
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				session, err := client.RefreshSession()
+				if err != nil {
+					t.Errorf("RefreshSession() error = %v", err)
+					return
+				}
+				sessions[i] = session
+			}(i)
+		}
+		wg.Wait()
+
+		if got := atomic.LoadInt32(&requests); got != 1 {
+			t.Errorf("RefreshSession() sent %d requests, want = 1", got)
+		}
+		for _, session := range sessions {
+			if session == nil || session.Token != "refreshed-access-token" {
+				t.Errorf("RefreshSession() = %v, want refreshed session", session)
+				return
+			}
+		}
+
+		sessions[0].Token = "changed"
+		if s := client.Session(); s == nil || s.Token != "refreshed-access-token" {
+			t.Errorf("RefreshSession() returns current session; session = %v", s)
+		}
+	})
+
+	t.Run("refresh without session", func(t *testing.T) {
+		client := NewClient("http://localhost")
+		_, err := client.RefreshSession()
+		if err == nil {
+			t.Errorf("RefreshSession() returns no error")
+		}
+	})
+}
+
+func TestClient_WithContext(t *testing.T) {
+	t.Run("cancelled context aborts request", func(t *testing.T) {
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}))
+		defer server.Close()
+		defer close(release)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(time.Second/10, cancel)
+
+		client := NewClient(server.URL)
+		_, err := client.SignInWithEmailWithContext(ctx, testdata.MockUserEmail(), testdata.MockUserPassword())
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("SignInWithEmailWithContext() error = %v, want = %v", err, context.Canceled)
+		}
+	})
+}
+
+func TestClient_VerifyOTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var params gotrueapi.VerifyParams
+		_ = json.NewDecoder(r.Body).Decode(&params)
+		if r.URL.Path != "/verify" || params.Token != "123456" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"code":403,"msg":"Token has expired or is invalid"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(&gotrueapi.Session{
+			Token:        "access-token",
+			TokenType:    "bearer",
+			ExpiresIn:    3600,
+			RefreshToken: "refresh-token",
+			User:         &gotrueapi.User{Email: params.Email},
+		})
+	}))
+	defer server.Close()
+
+	t.Run("recovery fires password recovery event", func(t *testing.T) {
+		client := NewClient(server.URL, WithAutoRefresh(false))
+
+		var ch = make(chan struct{}, 1)
+		unsubscribe := client.Subscribe(PasswordRecoveryEvent, func() {
+			ch <- struct{}{}
+		})
+		defer unsubscribe()
+
+		email := testdata.MockUserEmail()
+		_, err := client.VerifyOTP(&gotrueapi.VerifyParams{
+			Type:  gotrueapi.VerifyTypeRecovery,
+			Email: email,
+			Token: "123456",
+		})
+		if err != nil {
+			t.Errorf("VerifyOTP() error = %v", err)
+			return
+		}
+		if u := client.User(); u == nil || u.Email != email {
+			t.Errorf("VerifyOTP() does not save session; user = %v", u)
+		}
+
+		select {
+		case <-ch:
+		case <-time.After(time.Second):
+			t.Errorf("VerifyOTP() does not fire password recovery event")
+		}
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		client := NewClient(server.URL, WithAutoRefresh(false))
+		_, err := client.VerifyOTP(&gotrueapi.VerifyParams{
+			Type:  gotrueapi.VerifyTypeSMS,
+			Phone: testdata.MockUserPhone(),
+			Token: "000000",
+		})
+		if err == nil {
+			t.Errorf("VerifyOTP() returns no error")
+		}
+	})
+
+	t.Run("sms without phone", func(t *testing.T) {
+		client := NewClient(server.URL, WithAutoRefresh(false))
+		_, err := client.VerifyOTP(&gotrueapi.VerifyParams{
+			Type:  gotrueapi.VerifyTypeSMS,
+			Email: testdata.MockUserEmail(),
+			Token: "123456",
+		})
+		if err == nil {
+			t.Errorf("VerifyOTP() returns no error")
+		}
+	})
+}
+
+func TestClient_Settings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/settings" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+		_, _ = w.Write([]byte(`{"external":{"email":true,"phone":false,"github":true},"disable_signup":false,"sms_provider":"twilio"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithAutoRefresh(false))
+
+	settings, err := client.Settings()
+	if err != nil {
+		t.Errorf("Settings() error = %v", err)
+		return
+	}
+	if got := settings.EnabledProviders(); len(got) != 2 || got[0] != "email" || got[1] != "github" {
+		t.Errorf("EnabledProviders() = %v, want = [email github]", got)
+	}
+
+	_, err = client.SignUpWithPhone(testdata.MockUserPhone(), testdata.MockUserPassword(), nil)
+	if !errors.Is(err, ErrProviderDisabled) {
+		t.Errorf("SignUpWithPhone() error = %v, want = %v", err, ErrProviderDisabled)
+	}
+	t.Run("unlisted provider", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/settings":
+				_, _ = w.Write([]byte(`{"external":{"github":true}}`))
+			case "/otp":
+				_, _ = w.Write([]byte(`{}`))
+			default:
+				t.Errorf("unexpected request %s %s", r.Method, r.URL)
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL, WithAutoRefresh(false))
+		if err := client.SignInWithOTP(&gotrueapi.OTPParams{Email: testdata.MockUserEmail()}); err != nil {
+			t.Errorf("SignInWithOTP() error = %v", err)
+		}
+	})
+}
+
+func TestClient_loadSettings(t *testing.T) {
+	var settingsCalls, otpCalls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/settings":
+			atomic.AddInt32(&settingsCalls, 1)
+			w.WriteHeader(http.StatusInternalServerError)
+		case "/otp":
+			atomic.AddInt32(&otpCalls, 1)
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	t.Run("failed fetch", func(t *testing.T) {
+		atomic.StoreInt32(&settingsCalls, 0)
+		client := NewClient(server.URL, WithAutoRefresh(false))
+
+		for i := 0; i < 3; i++ {
+			if err := client.SignInWithOTP(&gotrueapi.OTPParams{Email: testdata.MockUserEmail()}); err != nil {
+				t.Errorf("SignInWithOTP() error = %v", err)
+				return
+			}
+		}
+		if got := atomic.LoadInt32(&settingsCalls); got != 1 {
+			t.Errorf("settings fetched %d times, want = 1", got)
+		}
+	})
+
+	t.Run("preflight disabled", func(t *testing.T) {
+		atomic.StoreInt32(&settingsCalls, 0)
+		client := NewClient(server.URL, WithAutoRefresh(false), WithSettingsPreflight(false))
+
+		if err := client.SignInWithOTP(&gotrueapi.OTPParams{Email: testdata.MockUserEmail()}); err != nil {
+			t.Errorf("SignInWithOTP() error = %v", err)
+			return
+		}
+		if got := atomic.LoadInt32(&settingsCalls); got != 0 {
+			t.Errorf("settings fetched %d times, want = 0", got)
+		}
+	})
+}
+
+func TestClient_Claims(t *testing.T) {
+	client := NewClient("http://localhost", WithAutoRefresh(false))
+
+	if _, err := client.Claims(); err == nil {
+		t.Errorf("Claims() without session returns no error")
+	}
+
+	client.Lock()
+	client.saveSession(&gotrueapi.Session{
+		Token:        testdata.AdminToken,
+		ExpiresIn:    3600,
+		RefreshToken: "refresh-token",
+	})
+	client.Unlock()
+
+	claims, err := client.Claims()
+	if err != nil {
+		t.Errorf("Claims() error = %v", err)
+		return
+	}
+	if claims.Role != "supabase_admin" || claims.Subject != "1234567890" {
+		t.Errorf("Claims() = %+v", claims)
+	}
+}
+
+func TestClient_SignInWithIDToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/settings":
+			_, _ = w.Write([]byte(`{"external":{"google":true,"apple":false}}`))
+		case "/token":
+			var params gotrueapi.TokenWithIDTokenGrantParams
+			_ = json.NewDecoder(r.Body).Decode(&params)
+			if r.URL.Query().Get("grant_type") != "id_token" || params.AccessToken != "google-access-token" {
+				t.Errorf("IssueTokenWithIDToken() body = %+v", params)
+			}
+			_ = json.NewEncoder(w).Encode(&gotrueapi.Session{
+				Token:        "access-token",
+				TokenType:    "bearer",
+				ExpiresIn:    3600,
+				RefreshToken: "refresh-token",
+				User:         &gotrueapi.User{},
+			})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	idToken := func(claims jwt.MapClaims) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("secret"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	client := NewClient(server.URL, WithAutoRefresh(false))
+
+	t.Run("google with access token", func(t *testing.T) {
+		var ch = make(chan struct{}, 1)
+		unsubscribe := client.Subscribe(SignedInEvent, func() {
+			ch <- struct{}{}
+		})
+		defer unsubscribe()
+
+		_, err := client.SignInWithIDToken(&gotrueapi.TokenWithIDTokenGrantParams{
+			Provider:    "google",
+			IdToken:     idToken(jwt.MapClaims{"nonce": "hashed", "at_hash": "hash"}),
+			Nonce:       "raw",
+			AccessToken: "google-access-token",
+		})
+		if err != nil {
+			t.Errorf("SignInWithIDToken() error = %v", err)
+			return
+		}
+		if s := client.Session(); s == nil || s.Token != "access-token" {
+			t.Errorf("SignInWithIDToken() does not save session; session = %v", s)
+		}
+
+		select {
+		case <-ch:
+		case <-time.After(time.Second):
+			t.Errorf("SignInWithIDToken() does not fire signed in event")
+		}
+	})
+
+	t.Run("missing nonce", func(t *testing.T) {
+		_, err := client.SignInWithIDToken(&gotrueapi.TokenWithIDTokenGrantParams{
+			Provider: "google",
+			IdToken:  idToken(jwt.MapClaims{"nonce": "hashed"}),
+		})
+		if err == nil {
+			t.Errorf("SignInWithIDToken() returns no error")
+		}
+	})
+
+	t.Run("disabled provider", func(t *testing.T) {
+		_, err := client.SignInWithIDToken(&gotrueapi.TokenWithIDTokenGrantParams{
+			Provider: "apple",
+			IdToken:  idToken(jwt.MapClaims{}),
+		})
+		if !errors.Is(err, ErrProviderDisabled) {
+			t.Errorf("SignInWithIDToken() error = %v, want = %v", err, ErrProviderDisabled)
+		}
+	})
+}
+
+func TestClient_Reauthenticate(t *testing.T) {
+	session := mockSessionWithAAL(t, AAL1, nil)
+
+	var reauthenticated int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /reauthenticate":
+			atomic.StoreInt32(&reauthenticated, 1)
+			_, _ = w.Write([]byte(`{}`))
+		case "PUT /user":
+			var params gotrueapi.PutUserParams
+			_ = json.NewDecoder(r.Body).Decode(&params)
+			if params.Nonce != "123456" || atomic.LoadInt32(&reauthenticated) == 0 {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"code":400,"error_code":"reauthentication_needed","msg":"Password update requires reauthentication"}`))
+				return
+			}
+			_ = json.NewEncoder(w).Encode(&gotrueapi.User{})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithAutoRefresh(false))
+	client.Lock()
+	client.saveSession(session)
+	client.Unlock()
+
+	password := testdata.MockUserPassword()
+	_, err := client.UpdateUser(&gotrueapi.PutUserParams{Password: &password})
+	if !errors.Is(err, ErrReauthenticationNeeded) {
+		t.Errorf("UpdateUser() error = %v, want = %v", err, ErrReauthenticationNeeded)
+		return
+	}
+
+	if err := client.Reauthenticate(); err != nil {
+		t.Errorf("Reauthenticate() error = %v", err)
+		return
+	}
+
+	_, err = client.UpdateUser(&gotrueapi.PutUserParams{Password: &password, Nonce: "123456"})
+	if err != nil {
+		t.Errorf("UpdateUser() error = %v", err)
+	}
+}
+
+func TestClient_SignInAnonymously(t *testing.T) {
+	uid := uuid.New()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /settings":
+			_, _ = w.Write([]byte(`{"external":{"anonymous_users":true}}`))
+		case "POST /signup":
+			var params map[string]interface{}
+			_ = json.NewDecoder(r.Body).Decode(&params)
+			if _, ok := params["email"]; ok {
+				t.Errorf("SignInAnonymously() body = %v", params)
+			}
+			_ = json.NewEncoder(w).Encode(&gotrueapi.Session{
+				Token:        "anonymous-access-token",
+				ExpiresIn:    3600,
+				RefreshToken: "anonymous-refresh-token",
+				User:         &gotrueapi.User{ID: uid, IsAnonymous: true},
+			})
+		case "PUT /user":
+			var params gotrueapi.PutUserParams
+			_ = json.NewDecoder(r.Body).Decode(&params)
+			_ = json.NewEncoder(w).Encode(&gotrueapi.User{ID: uid, Email: params.Email})
+		case "POST /token":
+			_ = json.NewEncoder(w).Encode(&gotrueapi.Session{
+				Token:        "access-token",
+				ExpiresIn:    3600,
+				RefreshToken: "refresh-token",
+				User:         &gotrueapi.User{ID: uid},
+			})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithAutoRefresh(false))
+
+	session, err := client.SignInAnonymously(map[string]interface{}{"cart": "1"}, "")
+	if err != nil {
+		t.Errorf("SignInAnonymously() error = %v", err)
+		return
+	}
+	if !session.User.IsAnonymous {
+		t.Errorf("SignInAnonymously() user = %+v, want anonymous", session.User)
+	}
+
+	var ch = make(chan struct{}, 1)
+	unsubscribe := client.Subscribe(TokenRefreshedEvent, func() {
+		ch <- struct{}{}
+	})
+	defer unsubscribe()
+
+	email := testdata.MockUserEmail()
+	user, err := client.UpdateUser(&gotrueapi.PutUserParams{Email: email})
+	if err != nil {
+		t.Errorf("UpdateUser() error = %v", err)
+		return
+	}
+	if user.ID != uid || user.IsAnonymous {
+		t.Errorf("UpdateUser() = %+v, want permanent user %v", user, uid)
+	}
+
+	select {
+	case <-ch:
+	case <-time.After(time.Second):
+		t.Errorf("UpdateUser() does not refresh the session of the converted user")
+		return
+	}
+	if s := client.Session(); s == nil || s.Token != "access-token" {
+		t.Errorf("Session() = %v", s)
+	}
+}
+
+func TestClient_SignInWithSSO(t *testing.T) {
+	var settings string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /settings":
+			_, _ = w.Write([]byte(settings))
+		case "POST /sso":
+			var params gotrueapi.SSOParams
+			_ = json.NewDecoder(r.Body).Decode(&params)
+			if params.Domain != "example.com" || !params.SkipHTTPRedirect || len(params.CodeChallenge) == 0 {
+				t.Errorf("SignInWithSSO() body = %+v", params)
+			}
+			_ = json.NewEncoder(w).Encode(&gotrueapi.SSOResponse{URL: "https://idp.example.com/sso"})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		settings string
+		wantErr  error
+	}{
+		{name: "saml enabled", settings: `{"saml_enabled":true}`},
+		{name: "saml not reported", settings: `{}`},
+		{name: "saml disabled", settings: `{"saml_enabled":false}`, wantErr: ErrProviderDisabled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings = tt.settings
+			client := NewClient(server.URL, WithAutoRefresh(false), WithFlowType(FlowTypePKCE))
+
+			u, err := client.SignInWithSSO(&gotrueapi.SSOParams{Domain: "example.com"})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("SignInWithSSO() error = %v, want = %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("SignInWithSSO() error = %v", err)
+				return
+			}
+			if u != "https://idp.example.com/sso" {
+				t.Errorf("SignInWithSSO() = %v", u)
+			}
+		})
+	}
+}
+
+func TestClient_Session(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /token":
+			atomic.AddInt32(&requests, 1)
+			_ = json.NewEncoder(w).Encode(&gotrueapi.Session{
+				Token:        "new-access-token",
+				ExpiresIn:    3600,
+				RefreshToken: "new-refresh-token",
+			})
+		case "GET /user":
+			_ = json.NewEncoder(w).Encode(&gotrueapi.User{})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	t.Run("refreshes within margin", func(t *testing.T) {
+		client := NewClient(server.URL, WithAutoRefresh(false), WithRefreshMargin(time.Minute))
+		client.Lock()
+		client.saveSession(&gotrueapi.Session{
+			Token:        "access-token",
+			ExpiresAt:    time.Now().Add(30 * time.Second).Unix(),
+			RefreshToken: "refresh-token",
+		})
+		client.Unlock()
+
+		session := client.Session()
+		if session == nil || session.Token != "new-access-token" {
+			t.Errorf("Session() = %v, want refreshed session", session)
+			return
+		}
+		if session.IsExpired(time.Minute) || !session.IsExpired(2*time.Hour) {
+			t.Errorf("Session() ExpiresAt = %v, want in an hour", time.Unix(session.ExpiresAt, 0))
+		}
+		if s := client.Session(); s == nil || s.Token != "new-access-token" || atomic.LoadInt32(&requests) != 1 {
+			t.Errorf("Session() refreshes fresh session")
+		}
+	})
+
+	t.Run("refresh fails on expired session", func(t *testing.T) {
+		unavailable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer unavailable.Close()
+
+		client := NewClient(unavailable.URL, WithAutoRefresh(false))
+		client.Lock()
+		client.saveSession(&gotrueapi.Session{
+			Token:        "access-token",
+			ExpiresAt:    time.Now().Add(-time.Minute).Unix(),
+			RefreshToken: "refresh-token",
+		})
+		client.Unlock()
+
+		if session, err := client.SessionWithContext(context.Background()); err == nil {
+			t.Errorf("SessionWithContext() = %v, want error", session)
+		}
+		if session := client.Session(); session == nil || session.Token != "access-token" {
+			t.Errorf("Session() = %v, want expired session", session)
+		}
+	})
+
+	t.Run("expires_at from url", func(t *testing.T) {
+		client := NewClient(server.URL, WithAutoRefresh(false))
+		expiresAt := time.Now().Add(10 * time.Minute).Unix()
+		session, err := client.GetSessionFromURL(
+			"http://localhost/#access_token=access-token&expires_in=3600&expires_at="+strconv.FormatInt(expiresAt, 10)+"&refresh_token=refresh-token&token_type=bearer",
+			false,
+		)
+		if err != nil {
+			t.Errorf("GetSessionFromURL() error = %v", err)
+			return
+		}
+		if session.ExpiresAt != expiresAt {
+			t.Errorf("GetSessionFromURL() ExpiresAt = %d, want = %d", session.ExpiresAt, expiresAt)
+		}
+	})
+	t.Run("store session from url concurrently", func(t *testing.T) {
+		client := NewClient(server.URL)
+		defer client.StopAutoRefresh()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 2; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := client.GetSessionFromURL("http://localhost/#access_token=access-token&expires_in=3600&refresh_token=refresh-token&token_type=bearer", true)
+				if err != nil {
+					t.Errorf("GetSessionFromURL() error = %v", err)
+				}
+			}()
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client.StopAutoRefresh()
+			_ = client.User()
+		}()
+		wg.Wait()
+
+		if s := client.Session(); s == nil || s.Token != "access-token" {
+			t.Errorf("GetSessionFromURL() does not save session; session = %v", s)
+		}
+	})
+}
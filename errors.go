@@ -0,0 +1,35 @@
+package gotrue
+
+import (
+	"github.com/ulbqb/gotrue-go/gotrueapi"
+)
+
+var (
+	// ErrSignupDisabled is returned when the server does not allow new users
+	// to sign up.
+	ErrSignupDisabled = gotrueapi.ErrSignupDisabled
+	// ErrProviderDisabled is returned when a sign in method is disabled on
+	// the server.
+	ErrProviderDisabled = gotrueapi.ErrProviderDisabled
+
+	// ErrInvalidCredentials is returned when the email, phone or password is
+	// wrong.
+	ErrInvalidCredentials = gotrueapi.ErrInvalidCredentials
+	// ErrUserAlreadyExists is returned when signing up or updating to an email
+	// or phone another user has.
+	ErrUserAlreadyExists = gotrueapi.ErrUserAlreadyExists
+	// ErrEmailNotConfirmed is returned when signing in before confirming the
+	// email.
+	ErrEmailNotConfirmed = gotrueapi.ErrEmailNotConfirmed
+	// ErrRateLimited is returned when the server throttles requests.
+	ErrRateLimited = gotrueapi.ErrRateLimited
+	// ErrSessionExpired is returned when the refresh token of the session is
+	// no longer valid.
+	ErrSessionExpired = gotrueapi.ErrSessionExpired
+	// ErrReauthenticationNeeded is returned when changing the password
+	// requires the nonce sent by Client.Reauthenticate.
+	ErrReauthenticationNeeded = gotrueapi.ErrReauthenticationNeeded
+	// ErrReauthenticationNotValid is returned when the nonce is wrong or has
+	// expired.
+	ErrReauthenticationNotValid = gotrueapi.ErrReauthenticationNotValid
+)
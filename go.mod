@@ -7,4 +7,5 @@ require (
 	github.com/google/uuid v1.3.0
 	github.com/pkg/errors v0.9.1
 	github.com/valyala/bytebufferpool v1.0.0
+	golang.org/x/oauth2 v0.24.0
 )
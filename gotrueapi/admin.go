@@ -1,18 +1,130 @@
 package gotrueapi
 
 import (
+	"context"
 	"net/http"
+	"net/url"
+	"strconv"
 
 	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
 	"github.com/ulbqb/gotrue-go/internal/reqbuilder"
 )
 
+type AdminListUsersParams struct {
+	Page    int
+	PerPage int
+	// Filter matches users by email or name.
+	Filter string
+}
+
+type UserList struct {
+	Users []User `json:"users"`
+	Aud   string `json:"aud"`
+
+	// Total is the number of users matching the filter, or 0 if unknown.
+	Total int `json:"-"`
+	// NextPage is the page following this one, or 0 if there is none or it
+	// is unknown.
+	NextPage int `json:"-"`
+}
+
+func AdminListUsers(host string, headers map[string]string, params *AdminListUsersParams) (*http.Request, error) {
+	return AdminListUsersWithContext(context.Background(), host, headers, params)
+}
+
+// AdminListUsersWithContext is like AdminListUsers but uses ctx for the request.
+func AdminListUsersWithContext(ctx context.Context, host string, headers map[string]string, params *AdminListUsersParams) (*http.Request, error) {
+	var page, perPage string
+	if params.Page > 0 {
+		page = strconv.Itoa(params.Page)
+	}
+	if params.PerPage > 0 {
+		perPage = strconv.Itoa(params.PerPage)
+	}
+
+	return reqbuilder.New().
+		Context(ctx).
+		Method("GET").
+		Host(host).
+		Path("/admin/users").
+		Headers(headers).
+		Queries("page", page).
+		Queries("per_page", perPage).
+		Queries("filter", url.QueryEscape(params.Filter)).
+		Build()
+}
+
+func AdminGetUser(host string, headers map[string]string, uid uuid.UUID) (*http.Request, error) {
+	return AdminGetUserWithContext(context.Background(), host, headers, uid)
+}
+
+// AdminGetUserWithContext is like AdminGetUser but uses ctx for the request.
+func AdminGetUserWithContext(ctx context.Context, host string, headers map[string]string, uid uuid.UUID) (*http.Request, error) {
+	return reqbuilder.New().
+		Context(ctx).
+		Method("GET").
+		Host(host).
+		Path("/admin/users/" + uid.String()).
+		Headers(headers).
+		Build()
+}
+
+type AdminCreateUserParams struct {
+	Email        string                 `json:"email,omitempty"`
+	Phone        string                 `json:"phone,omitempty"`
+	Password     string                 `json:"password,omitempty"`
+	EmailConfirm bool                   `json:"email_confirm,omitempty"`
+	PhoneConfirm bool                   `json:"phone_confirm,omitempty"`
+	UserMetadata map[string]interface{} `json:"user_metadata,omitempty"`
+	AppMetadata  map[string]interface{} `json:"app_metadata,omitempty"`
+	Role         string                 `json:"role,omitempty"`
+	// BanDuration is a duration such as "24h", or "none" to lift a ban.
+	BanDuration string `json:"ban_duration,omitempty"`
+}
+
+func AdminCreateUser(host string, headers map[string]string, params *AdminCreateUserParams) (*http.Request, error) {
+	return AdminCreateUserWithContext(context.Background(), host, headers, params)
+}
+
+// AdminCreateUserWithContext is like AdminCreateUser but uses ctx for the request.
+func AdminCreateUserWithContext(ctx context.Context, host string, headers map[string]string, params *AdminCreateUserParams) (*http.Request, error) {
+	if len(params.Email) == 0 && len(params.Phone) == 0 {
+		return nil, errors.New("api: email or phone should be provided")
+	}
+
+	return reqbuilder.New().
+		Context(ctx).
+		Method("POST").
+		Host(host).
+		Path("/admin/users").
+		Headers(headers).
+		Body(params).
+		Build()
+}
+
 type UpdateUserByIdParams struct {
-	AppMetadata map[string]interface{} `json:"app_metadata,omitempty"`
+	Email        string                 `json:"email,omitempty"`
+	Phone        string                 `json:"phone,omitempty"`
+	Password     string                 `json:"password,omitempty"`
+	EmailConfirm bool                   `json:"email_confirm,omitempty"`
+	PhoneConfirm bool                   `json:"phone_confirm,omitempty"`
+	UserMetadata map[string]interface{} `json:"user_metadata,omitempty"`
+	AppMetadata  map[string]interface{} `json:"app_metadata,omitempty"`
+	Role         string                 `json:"role,omitempty"`
+	// BanDuration is a duration such as "24h", or "none" to lift a ban.
+	BanDuration string `json:"ban_duration,omitempty"`
 }
 
 func UpdateUserById(host string, headers map[string]string, uid uuid.UUID, params *UpdateUserByIdParams) (*http.Request, error) {
+	return UpdateUserByIdWithContext(context.Background(), host, headers, uid, params)
+}
+
+// UpdateUserByIdWithContext is like UpdateUserById but uses ctx for the request.
+func UpdateUserByIdWithContext(ctx context.Context, host string, headers map[string]string, uid uuid.UUID, params *UpdateUserByIdParams) (*http.Request, error) {
 	return reqbuilder.New().
+		Context(ctx).
 		Method("PUT").
 		Host(host).
 		Path("/admin/users/" + uid.String()).
@@ -20,3 +132,109 @@ func UpdateUserById(host string, headers map[string]string, uid uuid.UUID, param
 		Body(params).
 		Build()
 }
+
+func AdminDeleteUser(host string, headers map[string]string, uid uuid.UUID) (*http.Request, error) {
+	return AdminDeleteUserWithContext(context.Background(), host, headers, uid)
+}
+
+// AdminDeleteUserWithContext is like AdminDeleteUser but uses ctx for the request.
+func AdminDeleteUserWithContext(ctx context.Context, host string, headers map[string]string, uid uuid.UUID) (*http.Request, error) {
+	return reqbuilder.New().
+		Context(ctx).
+		Method("DELETE").
+		Host(host).
+		Path("/admin/users/" + uid.String()).
+		Headers(headers).
+		Build()
+}
+
+type GenerateLinkType string
+
+const (
+	GenerateLinkTypeSignup             GenerateLinkType = "signup"
+	GenerateLinkTypeInvite             GenerateLinkType = "invite"
+	GenerateLinkTypeMagicLink          GenerateLinkType = "magiclink"
+	GenerateLinkTypeRecovery           GenerateLinkType = "recovery"
+	GenerateLinkTypeEmailChangeCurrent GenerateLinkType = "email_change_current"
+	GenerateLinkTypeEmailChangeNew     GenerateLinkType = "email_change_new"
+)
+
+type GenerateLinkParams struct {
+	Type     GenerateLinkType       `json:"type"`
+	Email    string                 `json:"email"`
+	NewEmail string                 `json:"new_email,omitempty"`
+	Password string                 `json:"password,omitempty"`
+	Data     map[string]interface{} `json:"data,omitempty"`
+
+	RedirectTo string `json:"redirect_to,omitempty"`
+}
+
+type GenerateLinkResponse struct {
+	User
+
+	ActionLink       string `json:"action_link"`
+	EmailOTP         string `json:"email_otp"`
+	HashedToken      string `json:"hashed_token"`
+	VerificationType string `json:"verification_type"`
+	RedirectTo       string `json:"redirect_to"`
+}
+
+func AdminGenerateLink(host string, headers map[string]string, params *GenerateLinkParams) (*http.Request, error) {
+	return AdminGenerateLinkWithContext(context.Background(), host, headers, params)
+}
+
+// AdminGenerateLinkWithContext is like AdminGenerateLink but uses ctx for the request.
+func AdminGenerateLinkWithContext(ctx context.Context, host string, headers map[string]string, params *GenerateLinkParams) (*http.Request, error) {
+	if len(params.Type) == 0 {
+		return nil, errors.New("api: link type should be provided")
+	}
+	if len(params.Email) == 0 {
+		return nil, errors.New("api: email should be provided")
+	}
+	if len(params.Password) == 0 && params.Type == GenerateLinkTypeSignup {
+		return nil, errors.New("api: password is required")
+	}
+	if len(params.NewEmail) == 0 &&
+		(params.Type == GenerateLinkTypeEmailChangeCurrent || params.Type == GenerateLinkTypeEmailChangeNew) {
+		return nil, errors.New("api: new email is required")
+	}
+
+	return reqbuilder.New().
+		Context(ctx).
+		Method("POST").
+		Host(host).
+		Path("/admin/generate_link").
+		Headers(headers).
+		Body(params).
+		Build()
+}
+
+func AdminListFactors(host string, headers map[string]string, uid uuid.UUID) (*http.Request, error) {
+	return AdminListFactorsWithContext(context.Background(), host, headers, uid)
+}
+
+// AdminListFactorsWithContext is like AdminListFactors but uses ctx for the request.
+func AdminListFactorsWithContext(ctx context.Context, host string, headers map[string]string, uid uuid.UUID) (*http.Request, error) {
+	return reqbuilder.New().
+		Context(ctx).
+		Method("GET").
+		Host(host).
+		Path("/admin/users/" + uid.String() + "/factors").
+		Headers(headers).
+		Build()
+}
+
+func AdminDeleteFactor(host string, headers map[string]string, uid, factorID uuid.UUID) (*http.Request, error) {
+	return AdminDeleteFactorWithContext(context.Background(), host, headers, uid, factorID)
+}
+
+// AdminDeleteFactorWithContext is like AdminDeleteFactor but uses ctx for the request.
+func AdminDeleteFactorWithContext(ctx context.Context, host string, headers map[string]string, uid, factorID uuid.UUID) (*http.Request, error) {
+	return reqbuilder.New().
+		Context(ctx).
+		Method("DELETE").
+		Host(host).
+		Path("/admin/users/" + uid.String() + "/factors/" + factorID.String()).
+		Headers(headers).
+		Build()
+}
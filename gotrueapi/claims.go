@@ -0,0 +1,40 @@
+package gotrueapi
+
+import (
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pkg/errors"
+)
+
+// AMREntry is an authentication method the session was authenticated with.
+type AMREntry struct {
+	Method    string `json:"method"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// Claims are the claims of an access token issued by GoTrue.
+type Claims struct {
+	jwt.RegisteredClaims
+
+	Email        string                 `json:"email"`
+	Phone        string                 `json:"phone"`
+	Role         string                 `json:"role"`
+	AppMetadata  map[string]interface{} `json:"app_metadata"`
+	UserMetaData map[string]interface{} `json:"user_metadata"`
+
+	// AAL is the authenticator assurance level, "aal1" or "aal2".
+	AAL         string     `json:"aal,omitempty"`
+	AMR         []AMREntry `json:"amr,omitempty"`
+	SessionID   string     `json:"session_id,omitempty"`
+	IsAnonymous bool       `json:"is_anonymous,omitempty"`
+}
+
+// DecodeClaims decodes the claims of token. The signature is NOT verified,
+// so the claims must only be trusted if the token is.
+func DecodeClaims(token string) (*Claims, error) {
+	var claims Claims
+	_, _, err := jwt.NewParser().ParseUnverified(token, &claims)
+	if err != nil {
+		return nil, errors.Wrap(err, "api: failed to decode token")
+	}
+	return &claims, nil
+}
@@ -0,0 +1,182 @@
+package gotrueapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrInvalidCredentials       = errors.New("gotrue: invalid credentials")
+	ErrUserAlreadyExists        = errors.New("gotrue: user already exists")
+	ErrEmailNotConfirmed        = errors.New("gotrue: email not confirmed")
+	ErrRateLimited              = errors.New("gotrue: rate limited")
+	ErrSessionExpired           = errors.New("gotrue: session expired")
+	ErrReauthenticationNeeded   = errors.New("gotrue: reauthentication needed")
+	ErrReauthenticationNotValid = errors.New("gotrue: reauthentication not valid")
+	ErrSignupDisabled           = errors.New("gotrue: sign up is disabled")
+	ErrProviderDisabled         = errors.New("gotrue: provider is disabled")
+)
+
+// errorCodes classifies the error codes of GoTrue.
+var errorCodes = map[string]error{
+	"invalid_credentials":        ErrInvalidCredentials,
+	"user_already_exists":        ErrUserAlreadyExists,
+	"email_exists":               ErrUserAlreadyExists,
+	"phone_exists":               ErrUserAlreadyExists,
+	"email_not_confirmed":        ErrEmailNotConfirmed,
+	"over_request_rate_limit":    ErrRateLimited,
+	"over_email_send_rate_limit": ErrRateLimited,
+	"over_sms_send_rate_limit":   ErrRateLimited,
+	"session_expired":            ErrSessionExpired,
+	"session_not_found":          ErrSessionExpired,
+	"refresh_token_not_found":    ErrSessionExpired,
+	"refresh_token_already_used": ErrSessionExpired,
+	"reauthentication_needed":    ErrReauthenticationNeeded,
+	"reauthentication_not_valid": ErrReauthenticationNotValid,
+	"signup_disabled":            ErrSignupDisabled,
+	"email_provider_disabled":    ErrProviderDisabled,
+	"phone_provider_disabled":    ErrProviderDisabled,
+	"provider_disabled":          ErrProviderDisabled,
+}
+
+// errorMessages classifies the messages of servers that predate error codes.
+var errorMessages = map[string]error{
+	"invalid login credentials":                 ErrInvalidCredentials,
+	"user already registered":                   ErrUserAlreadyExists,
+	"email not confirmed":                       ErrEmailNotConfirmed,
+	"invalid refresh token: not found":          ErrSessionExpired,
+	"invalid refresh token: already used":       ErrSessionExpired,
+	"password update requires reauthentication": ErrReauthenticationNeeded,
+	"signups not allowed for this instance":     ErrSignupDisabled,
+}
+
+// Error is an error response of GoTrue.
+type Error struct {
+	// Status is the HTTP status of the response.
+	Status int
+	// Code is the error code, such as "invalid_credentials". Servers that
+	// predate error codes may leave it empty.
+	Code    string
+	Message string
+	// RequestID identifies the request in the server logs.
+	RequestID string
+	// RetryAfter is how long the server asked to wait before retrying, from
+	// the Retry-After header.
+	RetryAfter time.Duration
+	// Body is the raw response body.
+	Body []byte
+}
+
+func (e *Error) Error() string {
+	if len(e.Code) > 0 {
+		return fmt.Sprintf("(%d) %s: %s", e.Status, e.Code, e.Message)
+	}
+	return fmt.Sprintf("(%d) %s", e.Status, e.Message)
+}
+
+// Is reports whether e is classified as target, one of the sentinel errors of
+// the package.
+func (e *Error) Is(target error) bool {
+	if target == ErrRateLimited && e.Status == http.StatusTooManyRequests {
+		return true
+	}
+	if kind, ok := errorCodes[e.Code]; ok {
+		return kind == target
+	}
+	if kind, ok := errorMessages[strings.ToLower(e.Message)]; ok {
+		return kind == target
+	}
+	return false
+}
+
+// errorBody covers the shapes of error responses GoTrue has returned:
+//
+//	{"code": 400, "msg": "...", "error_code": "..."}
+//	{"code": "...", "message": "..."}
+//	{"message": "...", "status": 400}
+//	{"error": "...", "error_description": "..."}
+type errorBody struct {
+	Code             json.RawMessage `json:"code"`
+	ErrorCode        string          `json:"error_code"`
+	Msg              string          `json:"msg"`
+	Message          string          `json:"message"`
+	Status           int             `json:"status"`
+	Error            string          `json:"error"`
+	ErrorDescription string          `json:"error_description"`
+}
+
+// NewError builds the Error of a response with status, header and body.
+func NewError(status int, header http.Header, body []byte) *Error {
+	e := &Error{
+		Status:    status,
+		RequestID: header.Get("X-Request-Id"),
+		Body:      body,
+	}
+	if len(e.RequestID) == 0 {
+		e.RequestID = header.Get("Sb-Request-Id")
+	}
+	e.RetryAfter = parseRetryAfter(header.Get("Retry-After"))
+
+	var b errorBody
+	if err := json.Unmarshal(body, &b); err != nil {
+		e.Message = strings.TrimSpace(string(body))
+		if len(e.Message) == 0 {
+			e.Message = http.StatusText(status)
+		}
+		return e
+	}
+
+	var code string
+	if len(b.Code) > 0 && b.Code[0] == '"' {
+		_ = json.Unmarshal(b.Code, &code)
+	}
+
+	switch {
+	case len(b.ErrorCode) > 0:
+		e.Code = b.ErrorCode
+	case len(code) > 0:
+		e.Code = code
+	default:
+		e.Code = b.Error
+	}
+
+	for _, msg := range []string{b.Msg, b.Message, b.ErrorDescription, b.Error} {
+		if len(msg) > 0 {
+			e.Message = msg
+			break
+		}
+	}
+	if len(e.Message) == 0 {
+		e.Message = string(bytes.TrimSpace(body))
+	}
+
+	if e.Status == 0 {
+		e.Status = b.Status
+	}
+
+	return e
+}
+
+// parseRetryAfter parses a Retry-After header of either delay seconds or an
+// HTTP date.
+func parseRetryAfter(v string) time.Duration {
+	if len(v) == 0 {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(v); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
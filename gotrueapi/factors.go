@@ -0,0 +1,125 @@
+package gotrueapi
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/ulbqb/gotrue-go/internal/reqbuilder"
+)
+
+type FactorType string
+
+const (
+	FactorTypeTOTP FactorType = "totp"
+)
+
+type FactorStatus string
+
+const (
+	FactorStatusVerified   FactorStatus = "verified"
+	FactorStatusUnverified FactorStatus = "unverified"
+)
+
+type EnrollFactorParams struct {
+	FactorType   FactorType `json:"factor_type"`
+	FriendlyName string     `json:"friendly_name,omitempty"`
+	Issuer       string     `json:"issuer,omitempty"`
+}
+
+type TOTP struct {
+	// QRCode is an SVG image of URI.
+	QRCode string `json:"qr_code"`
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+type EnrollFactorResponse struct {
+	ID           uuid.UUID  `json:"id"`
+	Type         FactorType `json:"type"`
+	FriendlyName string     `json:"friendly_name,omitempty"`
+	TOTP         TOTP       `json:"totp"`
+}
+
+func EnrollFactor(host string, headers map[string]string, params *EnrollFactorParams) (*http.Request, error) {
+	return EnrollFactorWithContext(context.Background(), host, headers, params)
+}
+
+// EnrollFactorWithContext is like EnrollFactor but uses ctx for the request.
+func EnrollFactorWithContext(ctx context.Context, host string, headers map[string]string, params *EnrollFactorParams) (*http.Request, error) {
+	if len(params.FactorType) == 0 {
+		return nil, errors.New("api: factor type should be provided")
+	}
+
+	return reqbuilder.New().
+		Context(ctx).
+		Method("POST").
+		Headers(headers).
+		Host(host).
+		Path("/factors").
+		Body(params).
+		Build()
+}
+
+type Challenge struct {
+	ID uuid.UUID `json:"id"`
+	// ExpiresAt is the unix time the challenge expires at.
+	ExpiresAt int64 `json:"expires_at"`
+}
+
+func ChallengeFactor(host string, headers map[string]string, factorID uuid.UUID) (*http.Request, error) {
+	return ChallengeFactorWithContext(context.Background(), host, headers, factorID)
+}
+
+// ChallengeFactorWithContext is like ChallengeFactor but uses ctx for the request.
+func ChallengeFactorWithContext(ctx context.Context, host string, headers map[string]string, factorID uuid.UUID) (*http.Request, error) {
+	return reqbuilder.New().
+		Context(ctx).
+		Method("POST").
+		Headers(headers).
+		Host(host).
+		Path("/factors/" + factorID.String() + "/challenge").
+		Build()
+}
+
+type VerifyFactorParams struct {
+	ChallengeID uuid.UUID `json:"challenge_id"`
+	Code        string    `json:"code"`
+}
+
+func VerifyFactor(host string, headers map[string]string, factorID uuid.UUID, params *VerifyFactorParams) (*http.Request, error) {
+	return VerifyFactorWithContext(context.Background(), host, headers, factorID, params)
+}
+
+// VerifyFactorWithContext is like VerifyFactor but uses ctx for the request.
+func VerifyFactorWithContext(ctx context.Context, host string, headers map[string]string, factorID uuid.UUID, params *VerifyFactorParams) (*http.Request, error) {
+	if len(params.Code) == 0 {
+		return nil, errors.New("api: code should be provided")
+	}
+
+	return reqbuilder.New().
+		Context(ctx).
+		Method("POST").
+		Headers(headers).
+		Host(host).
+		Path("/factors/" + factorID.String() + "/verify").
+		Body(params).
+		Build()
+}
+
+func UnenrollFactor(host string, headers map[string]string, factorID uuid.UUID) (*http.Request, error) {
+	return UnenrollFactorWithContext(context.Background(), host, headers, factorID)
+}
+
+// UnenrollFactorWithContext is like UnenrollFactor but uses ctx for the request.
+func UnenrollFactorWithContext(ctx context.Context, host string, headers map[string]string, factorID uuid.UUID) (*http.Request, error) {
+	return reqbuilder.New().
+		Context(ctx).
+		Method("DELETE").
+		Headers(headers).
+		Host(host).
+		Path("/factors/" + factorID.String()).
+		Build()
+}
@@ -0,0 +1,68 @@
+package gotrueapi
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/ulbqb/gotrue-go/internal/reqbuilder"
+)
+
+type LinkIdentityParams struct {
+	Provider            string
+	RedirectTo          string
+	Scopes              string
+	CodeChallenge       string
+	CodeChallengeMethod string
+}
+
+type LinkIdentityResponse struct {
+	URL string `json:"url"`
+}
+
+// LinkIdentity asks for the URL to link an identity of the provider to the
+// user, instead of being redirected to it.
+func LinkIdentity(host string, headers map[string]string, params *LinkIdentityParams) (*http.Request, error) {
+	return LinkIdentityWithContext(context.Background(), host, headers, params)
+}
+
+// LinkIdentityWithContext is like LinkIdentity but uses ctx for the request.
+func LinkIdentityWithContext(ctx context.Context, host string, headers map[string]string, params *LinkIdentityParams) (*http.Request, error) {
+	if len(params.Provider) == 0 {
+		return nil, errors.New("api: provider should be provided")
+	}
+
+	return reqbuilder.New().
+		Context(ctx).
+		Method("GET").
+		Headers(headers).
+		Host(host).
+		Path("/user/identities/authorize").
+		Queries(
+			"provider", url.QueryEscape(params.Provider),
+			"redirect_to", url.QueryEscape(params.RedirectTo),
+			"scopes", url.QueryEscape(params.Scopes),
+			"code_challenge", url.QueryEscape(params.CodeChallenge),
+			"code_challenge_method", params.CodeChallengeMethod,
+			"skip_http_redirect", "true",
+		).
+		Build()
+}
+
+func UnlinkIdentity(host string, headers map[string]string, identityID uuid.UUID) (*http.Request, error) {
+	return UnlinkIdentityWithContext(context.Background(), host, headers, identityID)
+}
+
+// UnlinkIdentityWithContext is like UnlinkIdentity but uses ctx for the request.
+func UnlinkIdentityWithContext(ctx context.Context, host string, headers map[string]string, identityID uuid.UUID) (*http.Request, error) {
+	return reqbuilder.New().
+		Context(ctx).
+		Method("DELETE").
+		Headers(headers).
+		Host(host).
+		Path("/user/identities/" + identityID.String()).
+		Build()
+}
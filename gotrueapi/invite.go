@@ -0,0 +1,38 @@
+package gotrueapi
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"github.com/ulbqb/gotrue-go/internal/reqbuilder"
+)
+
+type InviteParams struct {
+	Email string                 `json:"email"`
+	Data  map[string]interface{} `json:"data,omitempty"`
+
+	RedirectTo string `json:"-"`
+}
+
+func Invite(host string, headers map[string]string, params *InviteParams) (*http.Request, error) {
+	return InviteWithContext(context.Background(), host, headers, params)
+}
+
+// InviteWithContext is like Invite but uses ctx for the request.
+func InviteWithContext(ctx context.Context, host string, headers map[string]string, params *InviteParams) (*http.Request, error) {
+	if len(params.Email) == 0 {
+		return nil, errors.New("api: email should be provided")
+	}
+
+	return reqbuilder.New().
+		Context(ctx).
+		Method("POST").
+		Host(host).
+		Path("/invite").
+		Headers(headers).
+		Queries("redirect_to", params.RedirectTo).
+		Body(params).
+		Build()
+}
@@ -1,13 +1,20 @@
 package gotrueapi
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/ulbqb/gotrue-go/internal/reqbuilder"
 )
 
 func Logout(host string, headers map[string]string) (*http.Request, error) {
+	return LogoutWithContext(context.Background(), host, headers)
+}
+
+// LogoutWithContext is like Logout but uses ctx for the request.
+func LogoutWithContext(ctx context.Context, host string, headers map[string]string) (*http.Request, error) {
 	return reqbuilder.New().
+		Context(ctx).
 		Method("POST").
 		Headers(headers).
 		Host(host).
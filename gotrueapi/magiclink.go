@@ -1,6 +1,7 @@
 package gotrueapi
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/pkg/errors"
@@ -13,15 +14,24 @@ type MagicLinkParams struct {
 
 	Email string `json:"email"`
 
+	CodeChallenge       string `json:"code_challenge,omitempty"`
+	CodeChallengeMethod string `json:"code_challenge_method,omitempty"`
+
 	RedirectTo string `json:"-"`
 }
 
 func MagicLink(host string, headers map[string]string, params *MagicLinkParams) (*http.Request, error) {
+	return MagicLinkWithContext(context.Background(), host, headers, params)
+}
+
+// MagicLinkWithContext is like MagicLink but uses ctx for the request.
+func MagicLinkWithContext(ctx context.Context, host string, headers map[string]string, params *MagicLinkParams) (*http.Request, error) {
 	if len(params.Email) == 0 {
 		return nil, errors.New("api: email should be provided")
 	}
 
 	return reqbuilder.New().
+		Context(ctx).
 		Method("POST").
 		Headers(headers).
 		Host(host).
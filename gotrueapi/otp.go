@@ -1,6 +1,7 @@
 package gotrueapi
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/pkg/errors"
@@ -15,10 +16,18 @@ type OTPParams struct {
 	Phone      string `json:"phone"`
 	CreateUser bool   `json:"create_user"`
 
+	CodeChallenge       string `json:"code_challenge,omitempty"`
+	CodeChallengeMethod string `json:"code_challenge_method,omitempty"`
+
 	RedirectTo string `json:"-"`
 }
 
 func OTP(host string, headers map[string]string, params *OTPParams) (*http.Request, error) {
+	return OTPWithContext(context.Background(), host, headers, params)
+}
+
+// OTPWithContext is like OTP but uses ctx for the request.
+func OTPWithContext(ctx context.Context, host string, headers map[string]string, params *OTPParams) (*http.Request, error) {
 	if len(params.Email) > 0 && len(params.Phone) > 0 {
 		return nil, errors.New("api: email and phone were provided at the same time")
 	}
@@ -27,6 +36,7 @@ func OTP(host string, headers map[string]string, params *OTPParams) (*http.Reque
 	}
 
 	return reqbuilder.New().
+		Context(ctx).
 		Method("POST").
 		Headers(headers).
 		Host(host).
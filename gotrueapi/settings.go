@@ -1,13 +1,50 @@
 package gotrueapi
 
 import (
+	"context"
 	"net/http"
+	"sort"
 
 	"github.com/ulbqb/gotrue-go/internal/reqbuilder"
 )
 
-func Settings(host string, headers map[string]string) (*http.Request, error) {
+type Settings struct {
+	// External tells whether each sign in method, such as "email", "phone"
+	// or "github", is enabled.
+	External          map[string]bool `json:"external"`
+	DisableSignup     bool            `json:"disable_signup"`
+	MailerAutoconfirm bool            `json:"mailer_autoconfirm"`
+	PhoneAutoconfirm  bool            `json:"phone_autoconfirm"`
+	SMSProvider       string          `json:"sms_provider"`
+	// SAMLEnabled is nil if the server does not report it.
+	SAMLEnabled *bool `json:"saml_enabled"`
+}
+
+// ProviderEnabled reports whether the sign in method is enabled.
+func (s *Settings) ProviderEnabled(provider string) bool {
+	return s.External[provider]
+}
+
+// EnabledProviders returns the enabled sign in methods in alphabetical order.
+func (s *Settings) EnabledProviders() []string {
+	var providers []string
+	for provider, enabled := range s.External {
+		if enabled {
+			providers = append(providers, provider)
+		}
+	}
+	sort.Strings(providers)
+	return providers
+}
+
+func GetSettings(host string, headers map[string]string) (*http.Request, error) {
+	return GetSettingsWithContext(context.Background(), host, headers)
+}
+
+// GetSettingsWithContext is like GetSettings but uses ctx for the request.
+func GetSettingsWithContext(ctx context.Context, host string, headers map[string]string) (*http.Request, error) {
 	return reqbuilder.New().
+		Context(ctx).
 		Method("GET").
 		Headers(headers).
 		Host(host).
@@ -1,6 +1,7 @@
 package gotrueapi
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/pkg/errors"
@@ -16,10 +17,18 @@ type SignUpParams struct {
 	Password string      `json:"password,omitempty"`
 	Data     interface{} `json:"data,omitempty"`
 
+	CodeChallenge       string `json:"code_challenge,omitempty"`
+	CodeChallengeMethod string `json:"code_challenge_method,omitempty"`
+
 	RedirectTo string `json:"-"`
 }
 
 func SignUp(host string, headers map[string]string, params *SignUpParams) (*http.Request, error) {
+	return SignUpWithContext(context.Background(), host, headers, params)
+}
+
+// SignUpWithContext is like SignUp but uses ctx for the request.
+func SignUpWithContext(ctx context.Context, host string, headers map[string]string, params *SignUpParams) (*http.Request, error) {
 	if len(params.Email) > 0 && len(params.Phone) > 0 {
 		return nil, errors.New("api: email and phone were provided at the same time")
 	}
@@ -31,6 +40,7 @@ func SignUp(host string, headers map[string]string, params *SignUpParams) (*http
 	}
 
 	return reqbuilder.New().
+		Context(ctx).
 		Method("POST").
 		Headers(headers).
 		Host(host).
@@ -39,3 +49,26 @@ func SignUp(host string, headers map[string]string, params *SignUpParams) (*http
 		Body(params).
 		Build()
 }
+
+type AnonymousSignUpParams struct {
+	Security Security    `json:"gotrue_meta_security,omitempty"`
+	Data     interface{} `json:"data,omitempty"`
+}
+
+// SignUpAnonymously creates an anonymous user. It needs anonymous sign ins
+// to be enabled on the server.
+func SignUpAnonymously(host string, headers map[string]string, params *AnonymousSignUpParams) (*http.Request, error) {
+	return SignUpAnonymouslyWithContext(context.Background(), host, headers, params)
+}
+
+// SignUpAnonymouslyWithContext is like SignUpAnonymously but uses ctx for the request.
+func SignUpAnonymouslyWithContext(ctx context.Context, host string, headers map[string]string, params *AnonymousSignUpParams) (*http.Request, error) {
+	return reqbuilder.New().
+		Context(ctx).
+		Method("POST").
+		Headers(headers).
+		Host(host).
+		Path("/signup").
+		Body(params).
+		Build()
+}
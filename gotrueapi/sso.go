@@ -0,0 +1,211 @@
+package gotrueapi
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/ulbqb/gotrue-go/internal/reqbuilder"
+)
+
+type SSOParams struct {
+	Security Security `json:"gotrue_meta_security,omitempty"`
+
+	// Either ProviderID or Domain selects the identity provider.
+	ProviderID *uuid.UUID `json:"provider_id,omitempty"`
+	Domain     string     `json:"domain,omitempty"`
+	RedirectTo string     `json:"redirect_to,omitempty"`
+
+	CodeChallenge       string `json:"code_challenge,omitempty"`
+	CodeChallengeMethod string `json:"code_challenge_method,omitempty"`
+
+	// SkipHTTPRedirect makes the server respond with the URL instead of
+	// redirecting to it.
+	SkipHTTPRedirect bool `json:"skip_http_redirect"`
+}
+
+type SSOResponse struct {
+	URL string `json:"url"`
+}
+
+func SSO(host string, headers map[string]string, params *SSOParams) (*http.Request, error) {
+	return SSOWithContext(context.Background(), host, headers, params)
+}
+
+// SSOWithContext is like SSO but uses ctx for the request.
+func SSOWithContext(ctx context.Context, host string, headers map[string]string, params *SSOParams) (*http.Request, error) {
+	if params.ProviderID != nil && len(params.Domain) > 0 {
+		return nil, errors.New("api: provider id and domain were provided at the same time")
+	}
+	if params.ProviderID == nil && len(params.Domain) == 0 {
+		return nil, errors.New("api: provider id or domain should be provided")
+	}
+
+	return reqbuilder.New().
+		Context(ctx).
+		Method("POST").
+		Headers(headers).
+		Host(host).
+		Path("/sso").
+		Body(params).
+		Build()
+}
+
+type SSOProviderType string
+
+const (
+	SSOProviderTypeSAML SSOProviderType = "saml"
+)
+
+// SAMLAttribute maps a SAML attribute to a claim. Name or Names picks the
+// attribute, and Default is used when it is missing.
+type SAMLAttribute struct {
+	Name    string      `json:"name,omitempty"`
+	Names   []string    `json:"names,omitempty"`
+	Default interface{} `json:"default,omitempty"`
+}
+
+// SAMLAttributeMapping maps SAML attributes to claims of the user by claim
+// name.
+type SAMLAttributeMapping struct {
+	Keys map[string]SAMLAttribute `json:"keys,omitempty"`
+}
+
+type SAMLProvider struct {
+	EntityID         string                `json:"entity_id"`
+	MetadataURL      string                `json:"metadata_url,omitempty"`
+	MetadataXML      string                `json:"metadata_xml,omitempty"`
+	AttributeMapping *SAMLAttributeMapping `json:"attribute_mapping,omitempty"`
+}
+
+type SSODomain struct {
+	ID        uuid.UUID `json:"id"`
+	Domain    string    `json:"domain"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type SSOProvider struct {
+	ID        uuid.UUID    `json:"id"`
+	SAML      SAMLProvider `json:"saml"`
+	Domains   []SSODomain  `json:"domains"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+type SSOProviderList struct {
+	Items []SSOProvider `json:"items"`
+}
+
+// CreateSSOProviderParams creates a provider from either its metadata URL or
+// its metadata XML. Users with an email of Domains sign in with it.
+type CreateSSOProviderParams struct {
+	Type             SSOProviderType       `json:"type"`
+	MetadataURL      string                `json:"metadata_url,omitempty"`
+	MetadataXML      string                `json:"metadata_xml,omitempty"`
+	Domains          []string              `json:"domains,omitempty"`
+	AttributeMapping *SAMLAttributeMapping `json:"attribute_mapping,omitempty"`
+}
+
+// UpdateSSOProviderParams updates the fields that are set. Domains replaces
+// the domains of the provider.
+type UpdateSSOProviderParams struct {
+	MetadataURL      string                `json:"metadata_url,omitempty"`
+	MetadataXML      string                `json:"metadata_xml,omitempty"`
+	Domains          []string              `json:"domains,omitempty"`
+	AttributeMapping *SAMLAttributeMapping `json:"attribute_mapping,omitempty"`
+}
+
+func AdminListSSOProviders(host string, headers map[string]string) (*http.Request, error) {
+	return AdminListSSOProvidersWithContext(context.Background(), host, headers)
+}
+
+// AdminListSSOProvidersWithContext is like AdminListSSOProviders but uses ctx for the request.
+func AdminListSSOProvidersWithContext(ctx context.Context, host string, headers map[string]string) (*http.Request, error) {
+	return reqbuilder.New().
+		Context(ctx).
+		Method("GET").
+		Host(host).
+		Path("/admin/sso/providers").
+		Headers(headers).
+		Build()
+}
+
+func AdminCreateSSOProvider(host string, headers map[string]string, params *CreateSSOProviderParams) (*http.Request, error) {
+	return AdminCreateSSOProviderWithContext(context.Background(), host, headers, params)
+}
+
+// AdminCreateSSOProviderWithContext is like AdminCreateSSOProvider but uses ctx for the request.
+func AdminCreateSSOProviderWithContext(ctx context.Context, host string, headers map[string]string, params *CreateSSOProviderParams) (*http.Request, error) {
+	if len(params.Type) == 0 {
+		return nil, errors.New("api: provider type should be provided")
+	}
+	if len(params.MetadataURL) > 0 && len(params.MetadataXML) > 0 {
+		return nil, errors.New("api: metadata url and metadata xml were provided at the same time")
+	}
+	if len(params.MetadataURL) == 0 && len(params.MetadataXML) == 0 {
+		return nil, errors.New("api: metadata url or metadata xml should be provided")
+	}
+
+	return reqbuilder.New().
+		Context(ctx).
+		Method("POST").
+		Host(host).
+		Path("/admin/sso/providers").
+		Headers(headers).
+		Body(params).
+		Build()
+}
+
+func AdminGetSSOProvider(host string, headers map[string]string, id uuid.UUID) (*http.Request, error) {
+	return AdminGetSSOProviderWithContext(context.Background(), host, headers, id)
+}
+
+// AdminGetSSOProviderWithContext is like AdminGetSSOProvider but uses ctx for the request.
+func AdminGetSSOProviderWithContext(ctx context.Context, host string, headers map[string]string, id uuid.UUID) (*http.Request, error) {
+	return reqbuilder.New().
+		Context(ctx).
+		Method("GET").
+		Host(host).
+		Path("/admin/sso/providers/" + id.String()).
+		Headers(headers).
+		Build()
+}
+
+func AdminUpdateSSOProvider(host string, headers map[string]string, id uuid.UUID, params *UpdateSSOProviderParams) (*http.Request, error) {
+	return AdminUpdateSSOProviderWithContext(context.Background(), host, headers, id, params)
+}
+
+// AdminUpdateSSOProviderWithContext is like AdminUpdateSSOProvider but uses ctx for the request.
+func AdminUpdateSSOProviderWithContext(ctx context.Context, host string, headers map[string]string, id uuid.UUID, params *UpdateSSOProviderParams) (*http.Request, error) {
+	if len(params.MetadataURL) > 0 && len(params.MetadataXML) > 0 {
+		return nil, errors.New("api: metadata url and metadata xml were provided at the same time")
+	}
+
+	return reqbuilder.New().
+		Context(ctx).
+		Method("PUT").
+		Host(host).
+		Path("/admin/sso/providers/" + id.String()).
+		Headers(headers).
+		Body(params).
+		Build()
+}
+
+func AdminDeleteSSOProvider(host string, headers map[string]string, id uuid.UUID) (*http.Request, error) {
+	return AdminDeleteSSOProviderWithContext(context.Background(), host, headers, id)
+}
+
+// AdminDeleteSSOProviderWithContext is like AdminDeleteSSOProvider but uses ctx for the request.
+func AdminDeleteSSOProviderWithContext(ctx context.Context, host string, headers map[string]string, id uuid.UUID) (*http.Request, error) {
+	return reqbuilder.New().
+		Context(ctx).
+		Method("DELETE").
+		Host(host).
+		Path("/admin/sso/providers/" + id.String()).
+		Headers(headers).
+		Build()
+}
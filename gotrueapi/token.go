@@ -1,8 +1,10 @@
 package gotrueapi
 
 import (
+	"context"
 	"net/http"
 
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/pkg/errors"
 
 	"github.com/ulbqb/gotrue-go/internal/reqbuilder"
@@ -15,6 +17,11 @@ type TokenWithPasswordGrantParams struct {
 }
 
 func TokenWithPasswordGrant(host string, headers map[string]string, params *TokenWithPasswordGrantParams) (*http.Request, error) {
+	return TokenWithPasswordGrantWithContext(context.Background(), host, headers, params)
+}
+
+// TokenWithPasswordGrantWithContext is like TokenWithPasswordGrant but uses ctx for the request.
+func TokenWithPasswordGrantWithContext(ctx context.Context, host string, headers map[string]string, params *TokenWithPasswordGrantParams) (*http.Request, error) {
 	if len(params.Email) > 0 && len(params.Phone) > 0 {
 		return nil, errors.New("api: email and phone were provided at the same time")
 	}
@@ -23,6 +30,7 @@ func TokenWithPasswordGrant(host string, headers map[string]string, params *Toke
 	}
 
 	return reqbuilder.New().
+		Context(ctx).
 		Method("POST").
 		Headers(headers).
 		Host(host).
@@ -37,7 +45,13 @@ type TokenWithRefreshTokenGrantParams struct {
 }
 
 func TokenWithRefreshTokenGrant(host string, headers map[string]string, params *TokenWithRefreshTokenGrantParams) (*http.Request, error) {
+	return TokenWithRefreshTokenGrantWithContext(context.Background(), host, headers, params)
+}
+
+// TokenWithRefreshTokenGrantWithContext is like TokenWithRefreshTokenGrant but uses ctx for the request.
+func TokenWithRefreshTokenGrantWithContext(ctx context.Context, host string, headers map[string]string, params *TokenWithRefreshTokenGrantParams) (*http.Request, error) {
 	return reqbuilder.New().
+		Context(ctx).
 		Method("POST").
 		Headers(headers).
 		Host(host).
@@ -53,12 +67,40 @@ type TokenWithIDTokenGrantParams struct {
 	Provider string `json:"provider"`
 	ClientId string `json:"client_id"`
 	Issuer   string `json:"issuer"`
+	// AccessToken is the access token issued with the ID token, which the
+	// server checks against the at_hash claim of the ID token if it is set.
+	AccessToken string `json:"access_token,omitempty"`
 
 	RedirectTo string `json:"-"`
 }
 
 func TokenWithIDTokenGrant(host string, headers map[string]string, params *TokenWithIDTokenGrantParams) (*http.Request, error) {
+	return TokenWithIDTokenGrantWithContext(context.Background(), host, headers, params)
+}
+
+// TokenWithIDTokenGrantWithContext is like TokenWithIDTokenGrant but uses ctx for the request.
+func TokenWithIDTokenGrantWithContext(ctx context.Context, host string, headers map[string]string, params *TokenWithIDTokenGrantParams) (*http.Request, error) {
+	if len(params.Provider) == 0 {
+		return nil, errors.New("api: provider should be provided")
+	}
+	if len(params.IdToken) == 0 {
+		return nil, errors.New("api: id token should be provided")
+	}
+
+	// The raw nonce is required when the ID token has the hashed one. Tokens
+	// that cannot be decoded are left to the server.
+	var claims struct {
+		jwt.RegisteredClaims
+		Nonce string `json:"nonce"`
+	}
+	if _, _, err := new(jwt.Parser).ParseUnverified(params.IdToken, &claims); err == nil {
+		if len(claims.Nonce) > 0 && len(params.Nonce) == 0 {
+			return nil, errors.New("api: nonce should be provided for an id token with a nonce")
+		}
+	}
+
 	return reqbuilder.New().
+		Context(ctx).
 		Method("POST").
 		Headers(headers).
 		Host(host).
@@ -68,3 +110,34 @@ func TokenWithIDTokenGrant(host string, headers map[string]string, params *Token
 		Body(params).
 		Build()
 }
+
+const CodeChallengeMethodS256 = "S256"
+
+type TokenWithPKCEGrantParams struct {
+	AuthCode     string `json:"auth_code"`
+	CodeVerifier string `json:"code_verifier"`
+}
+
+func TokenWithPKCEGrant(host string, headers map[string]string, params *TokenWithPKCEGrantParams) (*http.Request, error) {
+	return TokenWithPKCEGrantWithContext(context.Background(), host, headers, params)
+}
+
+// TokenWithPKCEGrantWithContext is like TokenWithPKCEGrant but uses ctx for the request.
+func TokenWithPKCEGrantWithContext(ctx context.Context, host string, headers map[string]string, params *TokenWithPKCEGrantParams) (*http.Request, error) {
+	if len(params.AuthCode) == 0 {
+		return nil, errors.New("api: auth code should be provided")
+	}
+	if len(params.CodeVerifier) == 0 {
+		return nil, errors.New("api: code verifier should be provided")
+	}
+
+	return reqbuilder.New().
+		Context(ctx).
+		Method("POST").
+		Headers(headers).
+		Host(host).
+		Path("/token").
+		Queries("grant_type", "pkce").
+		Body(params).
+		Build()
+}
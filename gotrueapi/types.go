@@ -1,23 +1,14 @@
 package gotrueapi
 
 import (
-	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 )
 
-type Error struct {
-	Message string `json:"message"`
-	Status  int    `json:"status"`
-}
-
-func (e *Error) Error() string {
-	return fmt.Sprintf("(%d) %s", e.Status, e.Message)
-}
-
 type Identity struct {
 	ID           string                 `json:"id"`
+	IdentityID   uuid.UUID              `json:"identity_id"`
 	UserID       uuid.UUID              `json:"user_id"`
 	IdentityData map[string]interface{} `json:"identity_data,omitempty"`
 	Provider     string                 `json:"provider"`
@@ -26,6 +17,15 @@ type Identity struct {
 	UpdatedAt    time.Time              `json:"updated_at"`
 }
 
+type Factor struct {
+	ID           uuid.UUID    `json:"id"`
+	FriendlyName string       `json:"friendly_name,omitempty"`
+	FactorType   FactorType   `json:"factor_type"`
+	Status       FactorStatus `json:"status"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+}
+
 type User struct {
 	ID uuid.UUID `json:"id"`
 
@@ -54,16 +54,30 @@ type User struct {
 	UserMetaData map[string]interface{} `json:"user_metadata"`
 
 	Identities []Identity `json:"identities" has_many:"identities"`
+	Factors    []Factor   `json:"factors,omitempty" has_many:"factors"`
 
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at"`
 	BannedUntil *time.Time `json:"banned_until,omitempty"`
+
+	// IsAnonymous is set for users signed in anonymously until they add an
+	// email or phone.
+	IsAnonymous bool `json:"is_anonymous"`
 }
 
 type Session struct {
-	Token        string `json:"access_token"`
-	TokenType    string `json:"token_type"` // Bearer
-	ExpiresIn    int    `json:"expires_in"`
+	Token     string `json:"access_token"`
+	TokenType string `json:"token_type"` // Bearer
+	ExpiresIn int    `json:"expires_in"`
+	// ExpiresAt is when the access token expires in Unix time. It stays
+	// correct after the session is stored, unlike ExpiresIn.
+	ExpiresAt    int64  `json:"expires_at,omitempty"`
 	RefreshToken string `json:"refresh_token"`
 	User         *User  `json:"user"`
 }
+
+// IsExpired reports whether the access token expires within skew. A session
+// without ExpiresAt is considered expired.
+func (s *Session) IsExpired(skew time.Duration) bool {
+	return !time.Now().Add(skew).Before(time.Unix(s.ExpiresAt, 0))
+}
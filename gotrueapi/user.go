@@ -1,13 +1,20 @@
 package gotrueapi
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/ulbqb/gotrue-go/internal/reqbuilder"
 )
 
 func GetUser(host string, headers map[string]string) (*http.Request, error) {
+	return GetUserWithContext(context.Background(), host, headers)
+}
+
+// GetUserWithContext is like GetUser but uses ctx for the request.
+func GetUserWithContext(ctx context.Context, host string, headers map[string]string) (*http.Request, error) {
 	return reqbuilder.New().
+		Context(ctx).
 		Method("GET").
 		Headers(headers).
 		Host(host).
@@ -20,10 +27,19 @@ type PutUserParams struct {
 	Password *string                `json:"password"`
 	Data     map[string]interface{} `json:"data"`
 	Phone    string                 `json:"phone"`
+	// Nonce is the code sent by Reauthenticate. It is required to change the
+	// password when secure password change is enabled.
+	Nonce string `json:"nonce,omitempty"`
 }
 
 func PutUser(host string, headers map[string]string, params *PutUserParams) (*http.Request, error) {
+	return PutUserWithContext(context.Background(), host, headers, params)
+}
+
+// PutUserWithContext is like PutUser but uses ctx for the request.
+func PutUserWithContext(ctx context.Context, host string, headers map[string]string, params *PutUserParams) (*http.Request, error) {
 	return reqbuilder.New().
+		Context(ctx).
 		Method("PUT").
 		Host(host).
 		Path("/user").
@@ -31,3 +47,19 @@ func PutUser(host string, headers map[string]string, params *PutUserParams) (*ht
 		Body(params).
 		Build()
 }
+
+// Reauthenticate sends a nonce to the email or phone of the user.
+func Reauthenticate(host string, headers map[string]string) (*http.Request, error) {
+	return ReauthenticateWithContext(context.Background(), host, headers)
+}
+
+// ReauthenticateWithContext is like Reauthenticate but uses ctx for the request.
+func ReauthenticateWithContext(ctx context.Context, host string, headers map[string]string) (*http.Request, error) {
+	return reqbuilder.New().
+		Context(ctx).
+		Method("GET").
+		Headers(headers).
+		Host(host).
+		Path("/reauthenticate").
+		Build()
+}
@@ -0,0 +1,76 @@
+package gotrueapi
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"github.com/ulbqb/gotrue-go/internal/reqbuilder"
+)
+
+type VerifyType string
+
+const (
+	VerifyTypeSMS         VerifyType = "sms"
+	VerifyTypePhoneChange VerifyType = "phone_change"
+	VerifyTypeSignup      VerifyType = "signup"
+	VerifyTypeInvite      VerifyType = "invite"
+	VerifyTypeMagicLink   VerifyType = "magiclink"
+	VerifyTypeRecovery    VerifyType = "recovery"
+	VerifyTypeEmailChange VerifyType = "email_change"
+	VerifyTypeEmail       VerifyType = "email"
+)
+
+// IsPhone reports whether the type verifies a phone number.
+func (t VerifyType) IsPhone() bool {
+	return t == VerifyTypeSMS || t == VerifyTypePhoneChange
+}
+
+type VerifyParams struct {
+	Type VerifyType `json:"type"`
+
+	Email string `json:"email,omitempty"`
+	Phone string `json:"phone,omitempty"`
+	Token string `json:"token,omitempty"`
+	// TokenHash is the hashed token of an email link. Email and phone are
+	// not needed with it.
+	TokenHash string `json:"token_hash,omitempty"`
+
+	RedirectTo string `json:"redirect_to,omitempty"`
+}
+
+func Verify(host string, headers map[string]string, params *VerifyParams) (*http.Request, error) {
+	return VerifyWithContext(context.Background(), host, headers, params)
+}
+
+// VerifyWithContext is like Verify but uses ctx for the request.
+func VerifyWithContext(ctx context.Context, host string, headers map[string]string, params *VerifyParams) (*http.Request, error) {
+	if len(params.Type) == 0 {
+		return nil, errors.New("api: verification type should be provided")
+	}
+
+	if len(params.TokenHash) == 0 {
+		if len(params.Token) == 0 {
+			return nil, errors.New("api: token or token hash should be provided")
+		}
+		if len(params.Email) > 0 && len(params.Phone) > 0 {
+			return nil, errors.New("api: email and phone were provided at the same time")
+		}
+		if params.Type.IsPhone() && len(params.Phone) == 0 {
+			return nil, errors.New("api: phone should be provided")
+		}
+		if !params.Type.IsPhone() && len(params.Email) == 0 {
+			return nil, errors.New("api: email should be provided")
+		}
+	}
+
+	return reqbuilder.New().
+		Context(ctx).
+		Method("POST").
+		Headers(headers).
+		Host(host).
+		Path("/verify").
+		Body(params).
+		Build()
+}
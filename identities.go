@@ -0,0 +1,100 @@
+package gotrue
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/ulbqb/gotrue-go/gotrueapi"
+)
+
+// LinkIdentity returns the URL to send the signed in user to for linking an
+// identity of provider to their account. With the PKCE flow, the redirect has
+// to be completed with ExchangeCodeForSession.
+func (c *Client) LinkIdentity(provider Provider, redirectTo, scopes string) (string, error) {
+	return c.LinkIdentityWithContext(context.Background(), provider, redirectTo, scopes)
+}
+
+// LinkIdentityWithContext is like LinkIdentity but uses ctx for the requests.
+func (c *Client) LinkIdentityWithContext(ctx context.Context, provider Provider, redirectTo, scopes string) (string, error) {
+	token, err := c.accessToken(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	params := &gotrueapi.LinkIdentityParams{
+		Provider:   string(provider),
+		RedirectTo: redirectTo,
+		Scopes:     scopes,
+	}
+	challenge, err := c.codeChallenge()
+	if err != nil {
+		return "", err
+	}
+	if len(challenge) > 0 {
+		params.CodeChallenge = challenge
+		params.CodeChallengeMethod = gotrueapi.CodeChallengeMethodS256
+	}
+
+	return c.api.LinkIdentityWithContext(ctx, token, params)
+}
+
+// UnlinkIdentity removes identity from the signed in user. The user has to
+// keep at least one identity.
+func (c *Client) UnlinkIdentity(identity *gotrueapi.Identity) error {
+	return c.UnlinkIdentityWithContext(context.Background(), identity)
+}
+
+// UnlinkIdentityWithContext is like UnlinkIdentity but uses ctx for the requests.
+func (c *Client) UnlinkIdentityWithContext(ctx context.Context, identity *gotrueapi.Identity) error {
+	if identity == nil || identity.IdentityID == uuid.Nil {
+		return errors.New("gotrue: identity has no identity id")
+	}
+
+	token, err := c.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := c.api.UnlinkIdentityWithContext(ctx, token, identity.IdentityID); err != nil {
+		return err
+	}
+
+	user, err := c.api.GetUserWithContext(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	// The user may have signed out or in as someone else meanwhile.
+	if c.currentSession == nil || (c.currentUser != nil && c.currentUser.ID != user.ID) {
+		return nil
+	}
+	c.setUser(user)
+	c.eventChannel.Publish(UserUpdatedEvent)
+
+	return nil
+}
+
+// GetUserIdentities returns the identities of the signed in user.
+func (c *Client) GetUserIdentities() ([]gotrueapi.Identity, error) {
+	return c.GetUserIdentitiesWithContext(context.Background())
+}
+
+// GetUserIdentitiesWithContext is like GetUserIdentities but uses ctx for the requests.
+func (c *Client) GetUserIdentitiesWithContext(ctx context.Context) ([]gotrueapi.Identity, error) {
+	token, err := c.accessToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := c.api.GetUserWithContext(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return user.Identities, nil
+}
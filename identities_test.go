@@ -0,0 +1,99 @@
+package gotrue
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/ulbqb/gotrue-go/gotrueapi"
+)
+
+func TestClient_Identities(t *testing.T) {
+	session := mockSessionWithAAL(t, AAL1, nil)
+
+	var (
+		mu         sync.Mutex
+		identities = []gotrueapi.Identity{
+			{ID: "1", IdentityID: uuid.New(), Provider: "email"},
+			{ID: "2", IdentityID: uuid.New(), Provider: "github"},
+		}
+		githubID = identities[1].IdentityID
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+session.Token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch r.Method + " " + r.URL.Path {
+		case "GET /user/identities/authorize":
+			q := r.URL.Query()
+			if q.Get("provider") != "google" || q.Get("redirect_to") != "http://localhost/settings?tab=accounts" || q.Get("skip_http_redirect") != "true" {
+				t.Errorf("LinkIdentity() query = %s", r.URL.RawQuery)
+			}
+			_ = json.NewEncoder(w).Encode(&gotrueapi.LinkIdentityResponse{URL: "https://accounts.google.com/o/oauth2/auth"})
+		case "DELETE /user/identities/" + githubID.String():
+			identities = identities[:1]
+			_, _ = w.Write([]byte(`{}`))
+		case "GET /user":
+			_ = json.NewEncoder(w).Encode(&gotrueapi.User{Identities: identities})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithAutoRefresh(false))
+	client.Lock()
+	client.saveSession(session)
+	client.Unlock()
+
+	ctx := context.Background()
+
+	t.Run("link", func(t *testing.T) {
+		u, err := client.LinkIdentityWithContext(ctx, ProviderGoogle, "http://localhost/settings?tab=accounts", "")
+		if err != nil {
+			t.Errorf("LinkIdentity() error = %v", err)
+			return
+		}
+		if u != "https://accounts.google.com/o/oauth2/auth" {
+			t.Errorf("LinkIdentity() = %v", u)
+		}
+	})
+
+	t.Run("unlink", func(t *testing.T) {
+		got, err := client.GetUserIdentitiesWithContext(ctx)
+		if err != nil {
+			t.Errorf("GetUserIdentities() error = %v", err)
+			return
+		}
+		if len(got) != 2 {
+			t.Errorf("GetUserIdentities() = %v", got)
+			return
+		}
+
+		if err := client.UnlinkIdentityWithContext(ctx, &got[1]); err != nil {
+			t.Errorf("UnlinkIdentity() error = %v", err)
+			return
+		}
+		if u := client.User(); u == nil || len(u.Identities) != 1 {
+			t.Errorf("UnlinkIdentity() does not update user; user = %v", u)
+		}
+	})
+
+	t.Run("unlink without identity id", func(t *testing.T) {
+		if err := client.UnlinkIdentityWithContext(ctx, &gotrueapi.Identity{Provider: "github"}); err == nil {
+			t.Errorf("UnlinkIdentity() without identity id returns no error")
+		}
+	})
+}
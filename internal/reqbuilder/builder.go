@@ -2,6 +2,7 @@ package reqbuilder
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"io"
 	"net/http"
@@ -11,6 +12,7 @@ import (
 )
 
 type RequestBuilder struct {
+	ctx               context.Context
 	method            string
 	host              string
 	path              string
@@ -23,6 +25,11 @@ func New() *RequestBuilder {
 	return &RequestBuilder{}
 }
 
+func (b *RequestBuilder) Context(ctx context.Context) *RequestBuilder {
+	b.ctx = ctx
+	return b
+}
+
 func (b *RequestBuilder) Method(method string) *RequestBuilder {
 	b.method = method
 	return b
@@ -87,7 +94,13 @@ func (b *RequestBuilder) Build() (*http.Request, error) {
 		bodyReader = bytes.NewReader(data)
 	}
 
-	req, err := http.NewRequest(
+	ctx := b.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
 		b.method,
 		string(pathBuf.B),
 		bodyReader,
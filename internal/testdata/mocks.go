@@ -8,23 +8,16 @@ import (
 	"strconv"
 
 	"github.com/golang-jwt/jwt/v4"
-)
 
-type GoTrueClaims struct {
-	jwt.RegisteredClaims
-	Email        string                 `json:"email"`
-	Phone        string                 `json:"phone"`
-	AppMetadata  map[string]interface{} `json:"app_metadata"`
-	UserMetaData map[string]interface{} `json:"user_metadata"`
-	Role         string                 `json:"role"`
-}
+	"github.com/ulbqb/gotrue-go/gotrueapi"
+)
 
 func MockAccessToken() string {
 	return mockAccessToken("anon_key")
 }
 
 func mockAccessToken(role string) string {
-	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &GoTrueClaims{
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &gotrueapi.Claims{
 		RegisteredClaims: jwt.RegisteredClaims{
 			Subject: "1234567890",
 		},
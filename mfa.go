@@ -0,0 +1,189 @@
+package gotrue
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/ulbqb/gotrue-go/gotrueapi"
+)
+
+const (
+	AAL1 = "aal1"
+	AAL2 = "aal2"
+)
+
+// MFAClient manages the multi-factor authentication of the signed in user.
+type MFAClient struct {
+	c *Client
+}
+
+// MFA returns the multi-factor authentication API of the client.
+func (c *Client) MFA() *MFAClient {
+	return &MFAClient{c: c}
+}
+
+// Enroll starts enrollment of a new factor. A TOTP factor has to be verified
+// with a code from the authenticator app before it can be used.
+func (m *MFAClient) Enroll(factorType gotrueapi.FactorType, friendlyName string) (*gotrueapi.EnrollFactorResponse, error) {
+	return m.EnrollWithContext(context.Background(), factorType, friendlyName)
+}
+
+// EnrollWithContext is like Enroll but uses ctx for the requests.
+func (m *MFAClient) EnrollWithContext(ctx context.Context, factorType gotrueapi.FactorType, friendlyName string) (*gotrueapi.EnrollFactorResponse, error) {
+	token, err := m.c.accessToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.c.api.EnrollFactorWithContext(ctx, token, &gotrueapi.EnrollFactorParams{
+		FactorType:   factorType,
+		FriendlyName: friendlyName,
+	})
+}
+
+// Challenge creates a challenge to verify with a code of the factor.
+func (m *MFAClient) Challenge(factorID uuid.UUID) (*gotrueapi.Challenge, error) {
+	return m.ChallengeWithContext(context.Background(), factorID)
+}
+
+// ChallengeWithContext is like Challenge but uses ctx for the requests.
+func (m *MFAClient) ChallengeWithContext(ctx context.Context, factorID uuid.UUID) (*gotrueapi.Challenge, error) {
+	token, err := m.c.accessToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.c.api.ChallengeFactorWithContext(ctx, token, factorID)
+}
+
+// Verify verifies a challenge with code. On success the session is upgraded
+// to AAL2.
+func (m *MFAClient) Verify(factorID, challengeID uuid.UUID, code string) (*gotrueapi.Session, error) {
+	return m.VerifyWithContext(context.Background(), factorID, challengeID, code)
+}
+
+// VerifyWithContext is like Verify but uses ctx for the requests.
+func (m *MFAClient) VerifyWithContext(ctx context.Context, factorID, challengeID uuid.UUID, code string) (*gotrueapi.Session, error) {
+	c := m.c
+	token, err := c.accessToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := c.api.VerifyFactorWithContext(ctx, token, factorID, &gotrueapi.VerifyFactorParams{
+		ChallengeID: challengeID,
+		Code:        code,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	// The user may have signed out or in as someone else meanwhile.
+	if c.currentSession == nil || (c.currentUser != nil && session.User != nil && c.currentUser.ID != session.User.ID) {
+		return session, nil
+	}
+	if session.User == nil {
+		session.User = c.currentUser
+	}
+	c.saveSession(session)
+	c.eventChannel.Publish(MFAChallengeVerifiedEvent)
+
+	return session, nil
+}
+
+// Unenroll removes the factor.
+func (m *MFAClient) Unenroll(factorID uuid.UUID) error {
+	return m.UnenrollWithContext(context.Background(), factorID)
+}
+
+// UnenrollWithContext is like Unenroll but uses ctx for the requests.
+func (m *MFAClient) UnenrollWithContext(ctx context.Context, factorID uuid.UUID) error {
+	c := m.c
+	token, err := c.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := c.api.UnenrollFactorWithContext(ctx, token, factorID); err != nil {
+		return err
+	}
+
+	// The factors of the user decide the next assurance level.
+	user, err := c.api.GetUserWithContext(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	// The user may have signed out or in as someone else meanwhile.
+	if c.currentSession == nil || (c.currentUser != nil && c.currentUser.ID != user.ID) {
+		return nil
+	}
+	c.setUser(user)
+	c.eventChannel.Publish(UserUpdatedEvent)
+
+	return nil
+}
+
+// ListFactors returns the factors of the user.
+func (m *MFAClient) ListFactors() ([]gotrueapi.Factor, error) {
+	return m.ListFactorsWithContext(context.Background())
+}
+
+// ListFactorsWithContext is like ListFactors but uses ctx for the requests.
+func (m *MFAClient) ListFactorsWithContext(ctx context.Context) ([]gotrueapi.Factor, error) {
+	token, err := m.c.accessToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := m.c.api.GetUserWithContext(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return user.Factors, nil
+}
+
+type AuthenticatorAssuranceLevel struct {
+	// CurrentLevel is the level of the current session.
+	CurrentLevel string
+	// NextLevel is the level the session can reach. It is AAL2 if the user
+	// has a verified factor.
+	NextLevel string
+	// CurrentAuthenticationMethods are the methods the session was
+	// authenticated with.
+	CurrentAuthenticationMethods []gotrueapi.AMREntry
+}
+
+// GetAuthenticatorAssuranceLevel returns the assurance level of the current
+// session from the claims of its access token.
+func (m *MFAClient) GetAuthenticatorAssuranceLevel() (*AuthenticatorAssuranceLevel, error) {
+	claims, err := m.c.Claims()
+	if err != nil {
+		return nil, err
+	}
+
+	aal := &AuthenticatorAssuranceLevel{
+		CurrentLevel:                 claims.AAL,
+		NextLevel:                    claims.AAL,
+		CurrentAuthenticationMethods: claims.AMR,
+	}
+
+	if user := m.c.User(); user != nil {
+		for _, factor := range user.Factors {
+			if factor.Status == gotrueapi.FactorStatusVerified {
+				aal.NextLevel = AAL2
+				break
+			}
+		}
+	}
+
+	return aal, nil
+}
@@ -0,0 +1,170 @@
+package gotrue
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+
+	"github.com/ulbqb/gotrue-go/gotrueapi"
+	"github.com/ulbqb/gotrue-go/internal/testdata"
+)
+
+func mockSessionWithAAL(t *testing.T, aal string, factors []gotrueapi.Factor) *gotrueapi.Session {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &gotrueapi.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   uuid.NewString(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+		Role: "authenticated",
+		AAL:  aal,
+	}).SignedString(testdata.GotrueJWTSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &gotrueapi.Session{
+		Token:        token,
+		TokenType:    "bearer",
+		ExpiresIn:    3600,
+		RefreshToken: "refresh-token-" + aal,
+		User:         &gotrueapi.User{Factors: factors},
+	}
+}
+
+func TestMFAClient(t *testing.T) {
+	var (
+		factorID    = uuid.New()
+		challengeID = uuid.New()
+		factors     = []gotrueapi.Factor{{
+			ID:         factorID,
+			FactorType: gotrueapi.FactorTypeTOTP,
+			Status:     gotrueapi.FactorStatusVerified,
+		}}
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /factors":
+			_ = json.NewEncoder(w).Encode(&gotrueapi.EnrollFactorResponse{
+				ID:   factorID,
+				Type: gotrueapi.FactorTypeTOTP,
+				TOTP: gotrueapi.TOTP{Secret: "SECRET", URI: "otpauth://totp/example"},
+			})
+		case "POST /factors/" + factorID.String() + "/challenge":
+			_ = json.NewEncoder(w).Encode(&gotrueapi.Challenge{ID: challengeID})
+		case "POST /factors/" + factorID.String() + "/verify":
+			_ = json.NewEncoder(w).Encode(mockSessionWithAAL(t, AAL2, factors))
+		case "DELETE /factors/" + factorID.String():
+			_, _ = w.Write([]byte(`{}`))
+		case "GET /user":
+			_ = json.NewEncoder(w).Encode(&gotrueapi.User{})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithAutoRefresh(false))
+	client.Lock()
+	client.saveSession(mockSessionWithAAL(t, AAL1, nil))
+	client.Unlock()
+
+	ctx := context.Background()
+
+	enrolled, err := client.MFA().EnrollWithContext(ctx, gotrueapi.FactorTypeTOTP, "phone")
+	if err != nil {
+		t.Errorf("Enroll() error = %v", err)
+		return
+	}
+	if enrolled.TOTP.Secret != "SECRET" {
+		t.Errorf("Enroll() = %+v", enrolled)
+	}
+
+	challenge, err := client.MFA().ChallengeWithContext(ctx, enrolled.ID)
+	if err != nil {
+		t.Errorf("Challenge() error = %v", err)
+		return
+	}
+
+	var ch = make(chan struct{}, 1)
+	unsubscribe := client.Subscribe(MFAChallengeVerifiedEvent, func() {
+		ch <- struct{}{}
+	})
+	defer unsubscribe()
+
+	_, err = client.MFA().VerifyWithContext(ctx, enrolled.ID, challenge.ID, "123456")
+	if err != nil {
+		t.Errorf("Verify() error = %v", err)
+		return
+	}
+
+	select {
+	case <-ch:
+	case <-time.After(time.Second):
+		t.Errorf("Verify() does not fire MFA challenge verified event")
+	}
+
+	aal, err := client.MFA().GetAuthenticatorAssuranceLevel()
+	if err != nil {
+		t.Errorf("GetAuthenticatorAssuranceLevel() error = %v", err)
+		return
+	}
+	if aal.CurrentLevel != AAL2 || aal.NextLevel != AAL2 {
+		t.Errorf("GetAuthenticatorAssuranceLevel() = %+v", aal)
+	}
+
+	if err := client.MFA().UnenrollWithContext(ctx, enrolled.ID); err != nil {
+		t.Errorf("Unenroll() error = %v", err)
+		return
+	}
+	if u := client.User(); u == nil || len(u.Factors) != 0 {
+		t.Errorf("Unenroll() does not update user; user = %v", u)
+	}
+}
+
+func TestMFAClient_Verify_refreshesToken(t *testing.T) {
+	var (
+		factorID    = uuid.New()
+		challengeID = uuid.New()
+		refreshed   = mockSessionWithAAL(t, AAL1, nil)
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /token":
+			_ = json.NewEncoder(w).Encode(refreshed)
+		case "POST /factors/" + factorID.String() + "/verify":
+			if r.Header.Get("Authorization") != "Bearer "+refreshed.Token {
+				w.WriteHeader(http.StatusUnauthorized)
+				_, _ = w.Write([]byte(`{"code":401,"msg":"invalid JWT"}`))
+				return
+			}
+			_ = json.NewEncoder(w).Encode(mockSessionWithAAL(t, AAL2, nil))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithAutoRefresh(false))
+	session := mockSessionWithAAL(t, AAL1, nil)
+	session.ExpiresAt = time.Now().Add(10 * time.Second).Unix()
+	client.Lock()
+	client.saveSession(session)
+	client.Unlock()
+
+	if _, err := client.MFA().Verify(factorID, challengeID, "123456"); err != nil {
+		t.Errorf("Verify() error = %v", err)
+		return
+	}
+	if claims, err := client.Claims(); err != nil || claims.AAL != AAL2 {
+		t.Errorf("Verify() does not save session; claims = %+v, error = %v", claims, err)
+	}
+}
@@ -0,0 +1,199 @@
+// Package middleware authenticates net/http requests with GoTrue access
+// tokens.
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ulbqb/gotrue-go/gotrueapi"
+)
+
+// DefaultCookieName is the cookie supabase clients keep the access token in.
+const DefaultCookieName = "sb-access-token"
+
+// Auth is the authentication of a request.
+type Auth struct {
+	Token  string
+	Claims *gotrueapi.Claims
+	User   *gotrueapi.User
+}
+
+// Predicate decides whether an authenticated request is allowed.
+type Predicate func(auth *Auth) bool
+
+// HasRole allows users with role in their token.
+func HasRole(role string) Predicate {
+	return func(auth *Auth) bool {
+		return auth.Claims != nil && auth.Claims.Role == role
+	}
+}
+
+// AppMetadataContains allows users whose app_metadata[key] is value, or is a
+// list containing value.
+func AppMetadataContains(key, value string) Predicate {
+	return func(auth *Auth) bool {
+		if auth.User == nil {
+			return false
+		}
+		switch v := auth.User.AppMetadata[key].(type) {
+		case string:
+			return v == value
+		case []interface{}:
+			for _, item := range v {
+				if item == value {
+					return true
+				}
+			}
+		case []string:
+			for _, item := range v {
+				if item == value {
+					return true
+				}
+			}
+		}
+		return false
+	}
+}
+
+type contextKey struct{}
+
+// FromContext returns the authentication attached to ctx by the middleware.
+func FromContext(ctx context.Context) (*Auth, bool) {
+	auth, ok := ctx.Value(contextKey{}).(*Auth)
+	return auth, ok
+}
+
+// UserFromContext returns the authenticated user attached to ctx.
+func UserFromContext(ctx context.Context) (*gotrueapi.User, bool) {
+	auth, ok := FromContext(ctx)
+	if !ok || auth.User == nil {
+		return nil, false
+	}
+	return auth.User, true
+}
+
+// ClaimsFromContext returns the claims of the access token attached to ctx.
+func ClaimsFromContext(ctx context.Context) (*gotrueapi.Claims, bool) {
+	auth, ok := FromContext(ctx)
+	if !ok || auth.Claims == nil {
+		return nil, false
+	}
+	return auth.Claims, true
+}
+
+// Middleware extracts access tokens from requests and validates them.
+type Middleware struct {
+	validator  Validator
+	cookieName string
+}
+
+// Option configures a Middleware.
+type Option func(*Middleware)
+
+// WithCookieName sets the cookie the access token is read from when there is
+// no Authorization header. It defaults to DefaultCookieName.
+func WithCookieName(name string) Option {
+	return func(m *Middleware) {
+		m.cookieName = name
+	}
+}
+
+func New(validator Validator, opts ...Option) *Middleware {
+	m := &Middleware{
+		validator:  validator,
+		cookieName: DefaultCookieName,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// RequireAuth responds 401 to requests without a valid access token and 403
+// to requests any of predicates rejects. It responds 503 if the validator
+// fails with ErrUnavailable.
+func (m *Middleware) RequireAuth(predicates ...Predicate) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := m.token(r)
+			if len(token) == 0 {
+				writeError(w, http.StatusUnauthorized, "missing access token")
+				return
+			}
+
+			auth, err := m.validator.Validate(r.Context(), token)
+			if errors.Is(err, ErrUnavailable) {
+				writeError(w, http.StatusServiceUnavailable, "authentication unavailable")
+				return
+			}
+			if err != nil {
+				writeError(w, http.StatusUnauthorized, "invalid access token")
+				return
+			}
+
+			for _, allowed := range predicates {
+				if !allowed(auth) {
+					writeError(w, http.StatusForbidden, "insufficient permissions")
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKey{}, auth)))
+		})
+	}
+}
+
+// OptionalAuth attaches the authentication of requests with a valid access
+// token and passes other requests through unauthenticated.
+func (m *Middleware) OptionalAuth() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if token := m.token(r); len(token) > 0 {
+				if auth, err := m.validator.Validate(r.Context(), token); err == nil {
+					r = r.WithContext(context.WithValue(r.Context(), contextKey{}, auth))
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (m *Middleware) token(r *http.Request) string {
+	if header := r.Header.Get("Authorization"); len(header) > 0 {
+		const prefix = "bearer "
+		if len(header) > len(prefix) && strings.EqualFold(header[:len(prefix)], prefix) {
+			return strings.TrimSpace(header[len(prefix):])
+		}
+		return ""
+	}
+
+	if cookie, err := r.Cookie(m.cookieName); err == nil {
+		return cookie.Value
+	}
+	return ""
+}
+
+// errorResponse is the body of error responses.
+type errorResponse struct {
+	Code    int    `json:"code"`
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	if status == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="gotrue"`)
+	}
+	w.Header().Set("Content-Type", "application/json;charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(&errorResponse{
+		Code:    status,
+		Error:   strings.ToLower(strings.ReplaceAll(http.StatusText(status), " ", "_")),
+		Message: message,
+	})
+}
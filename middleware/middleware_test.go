@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+
+	gotrue "github.com/ulbqb/gotrue-go"
+	"github.com/ulbqb/gotrue-go/gotrueapi"
+	"github.com/ulbqb/gotrue-go/internal/testdata"
+	"github.com/ulbqb/gotrue-go/verifier"
+)
+
+func mockToken(t *testing.T, uid uuid.UUID, appMetadata map[string]interface{}) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &gotrueapi.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   uid.String(),
+			Audience:  jwt.ClaimStrings{verifier.DefaultAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+		Role:        "authenticated",
+		AppMetadata: appMetadata,
+	}).SignedString(testdata.GotrueJWTSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestMiddleware(t *testing.T) {
+	m := New(LocalValidator(verifier.New(verifier.WithSecret(testdata.GotrueJWTSecret))))
+
+	uid := uuid.New()
+	var (
+		editor = mockToken(t, uid, map[string]interface{}{"roles": []string{"editor", "publisher"}})
+		viewer = mockToken(t, uid, map[string]interface{}{"roles": []string{"viewer"}})
+	)
+
+	handler := m.RequireAuth(AppMetadataContains("roles", "editor"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, ok := UserFromContext(r.Context())
+		if !ok || user.ID != uid {
+			t.Errorf("UserFromContext() = %v, %v", user, ok)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		name   string
+		header string
+		cookie string
+		want   int
+	}{
+		{name: "bearer token", header: "Bearer " + editor, want: http.StatusNoContent},
+		{name: "cookie", cookie: editor, want: http.StatusNoContent},
+		{name: "missing token", want: http.StatusUnauthorized},
+		{name: "invalid token", header: "Bearer " + testdata.MockAccessToken(), want: http.StatusUnauthorized},
+		{name: "rejected by predicate", header: "Bearer " + viewer, want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if len(tt.header) > 0 {
+				req.Header.Set("Authorization", tt.header)
+			}
+			if len(tt.cookie) > 0 {
+				req.AddCookie(&http.Cookie{Name: DefaultCookieName, Value: tt.cookie})
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want = %d; body = %s", rec.Code, tt.want, rec.Body)
+			}
+		})
+	}
+
+	t.Run("optional auth", func(t *testing.T) {
+		var authenticated bool
+		handler := m.OptionalAuth()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, authenticated = FromContext(r.Context())
+		}))
+
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+		if authenticated {
+			t.Errorf("OptionalAuth() authenticates request without token")
+		}
+	})
+}
+
+func TestRemoteValidator(t *testing.T) {
+	uid := uuid.New()
+	token := mockToken(t, uid, nil)
+
+	var status int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/user" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(`{"code":` + strconv.Itoa(status) + `,"msg":"error"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(&gotrueapi.User{ID: uid})
+	}))
+	defer server.Close()
+
+	m := New(RemoteValidator(gotrue.NewAPIClient(server.URL)))
+	handler := m.RequireAuth()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		name   string
+		status int
+		want   int
+	}{
+		{name: "valid token", status: http.StatusOK, want: http.StatusNoContent},
+		{name: "rejected token", status: http.StatusUnauthorized, want: http.StatusUnauthorized},
+		{name: "forbidden token", status: http.StatusForbidden, want: http.StatusUnauthorized},
+		{name: "token of another audience", status: http.StatusBadRequest, want: http.StatusUnauthorized},
+		{name: "deleted user", status: http.StatusNotFound, want: http.StatusUnauthorized},
+		{name: "rate limited", status: http.StatusTooManyRequests, want: http.StatusServiceUnavailable},
+		{name: "server error", status: http.StatusInternalServerError, want: http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status = tt.status
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want = %d; body = %s", rec.Code, tt.want, rec.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	gotrue "github.com/ulbqb/gotrue-go"
+	"github.com/ulbqb/gotrue-go/gotrueapi"
+	"github.com/ulbqb/gotrue-go/verifier"
+)
+
+// ErrUnavailable is returned by validators that could not decide whether a
+// token is valid, such as when GoTrue cannot be reached. RequireAuth responds
+// 503 to requests it fails.
+var ErrUnavailable = errors.New("middleware: validation unavailable")
+
+// Validator validates an access token and returns who it authenticates.
+type Validator interface {
+	Validate(ctx context.Context, token string) (*Auth, error)
+}
+
+// ValidatorFunc adapts a function to Validator.
+type ValidatorFunc func(ctx context.Context, token string) (*Auth, error)
+
+func (f ValidatorFunc) Validate(ctx context.Context, token string) (*Auth, error) {
+	return f(ctx, token)
+}
+
+// LocalValidator validates tokens with v, without calling GoTrue. The user is
+// built from the claims of the token.
+func LocalValidator(v *verifier.Verifier) Validator {
+	return ValidatorFunc(func(ctx context.Context, token string) (*Auth, error) {
+		claims, err := v.Verify(ctx, token)
+		if err != nil {
+			return nil, err
+		}
+		return &Auth{
+			Token:  token,
+			Claims: claims,
+			User:   userFromClaims(claims),
+		}, nil
+	})
+}
+
+// RemoteValidator validates tokens by fetching the user from GoTrue. Network
+// errors, 429 and 5xx responses wrap ErrUnavailable.
+func RemoteValidator(api *gotrue.APIClient) Validator {
+	return ValidatorFunc(func(ctx context.Context, token string) (*Auth, error) {
+		user, err := api.GetUserWithContext(ctx, token)
+		if err != nil {
+			if rejected(err) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+		}
+		// GoTrue accepted the token, so its claims can be trusted.
+		claims, err := gotrueapi.DecodeClaims(token)
+		if err != nil {
+			return nil, err
+		}
+		return &Auth{
+			Token:  token,
+			Claims: claims,
+			User:   user,
+		}, nil
+	})
+}
+
+// rejected reports whether GoTrue refused the token rather than failing to
+// validate it. Every 4xx but 429 is a refusal, such as 400 for a token of
+// another audience or 404 for a deleted user.
+func rejected(err error) bool {
+	var apiErr *gotrueapi.Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.Status >= 400 && apiErr.Status < 500 && apiErr.Status != http.StatusTooManyRequests
+}
+
+func userFromClaims(claims *gotrueapi.Claims) *gotrueapi.User {
+	id, _ := uuid.Parse(claims.Subject)
+
+	var aud string
+	if len(claims.Audience) > 0 {
+		aud = claims.Audience[0]
+	}
+
+	return &gotrueapi.User{
+		ID:           id,
+		Aud:          aud,
+		Role:         claims.Role,
+		Email:        claims.Email,
+		Phone:        claims.Phone,
+		AppMetadata:  claims.AppMetadata,
+		UserMetaData: claims.UserMetaData,
+	}
+}
@@ -0,0 +1,169 @@
+package gotrue
+
+import (
+	"net/http"
+	"time"
+)
+
+const defaultTimeout = 5 * time.Second
+
+// Logger logs diagnostics of background work such as automatic refresh and
+// session persistence. *log.Logger satisfies Logger.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+type options struct {
+	httpClient *http.Client
+	transport  http.RoundTripper
+	timeout    time.Duration
+	apiKey     string
+	userAgent  string
+
+	retryPolicy *RetryPolicy
+
+	autoRefresh   bool
+	refreshMargin time.Duration
+	flowType      FlowType
+	storage       SessionStorage
+	storageKey    string
+	logger        Logger
+
+	settingsPreflight bool
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		autoRefresh:   true,
+		refreshMargin: defaultRefreshMargin,
+		flowType:      FlowTypeImplicit,
+
+		settingsPreflight: true,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// newHTTPClient returns the HTTP client the options describe.
+func (o *options) newHTTPClient() *http.Client {
+	client := &http.Client{Timeout: defaultTimeout}
+	if o.httpClient != nil {
+		copied := *o.httpClient
+		client = &copied
+	}
+	if o.transport != nil {
+		client.Transport = o.transport
+	}
+	if o.timeout != 0 {
+		client.Timeout = o.timeout
+	}
+	return client
+}
+
+// Option configures a client.
+type Option func(*options)
+
+// WithHTTPClient sends requests with client. The client is copied, so later
+// changes to it have no effect.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = client
+	}
+}
+
+// WithTransport sends requests through transport.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(o *options) {
+		o.transport = transport
+	}
+}
+
+// WithTimeout sets the timeout of each request. It defaults to 5 seconds.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// WithAPIKey sends key in the apikey header of every request, and in the
+// Authorization header of requests not made on behalf of a user.
+func WithAPIKey(key string) Option {
+	return func(o *options) {
+		o.apiKey = key
+	}
+}
+
+// WithUserAgent sets the User-Agent header of every request.
+func WithUserAgent(userAgent string) Option {
+	return func(o *options) {
+		o.userAgent = userAgent
+	}
+}
+
+// WithRetryPolicy retries requests that are safe to repeat by policy. Requests
+// are not retried by default.
+func WithRetryPolicy(policy *RetryPolicy) Option {
+	return func(o *options) {
+		o.retryPolicy = policy
+	}
+}
+
+// WithAutoRefresh enables or disables automatic refresh of the session. It is
+// enabled by default.
+func WithAutoRefresh(enabled bool) Option {
+	return func(o *options) {
+		o.autoRefresh = enabled
+	}
+}
+
+// WithRefreshMargin sets how long before its access token expires the session
+// is refreshed, automatically and by Session. It defaults to 30 seconds.
+func WithRefreshMargin(margin time.Duration) Option {
+	return func(o *options) {
+		o.refreshMargin = margin
+	}
+}
+
+// WithFlowType sets how the client signs in through redirects: with tokens
+// in the redirect URL (FlowTypeImplicit, the default) or with a code to
+// exchange (FlowTypePKCE).
+func WithFlowType(flowType FlowType) Option {
+	return func(o *options) {
+		o.flowType = flowType
+	}
+}
+
+// WithSettingsPreflight enables or disables checking the server settings
+// before signing up or in, to fail fast with ErrSignupDisabled or
+// ErrProviderDisabled. It is enabled by default. The settings are fetched
+// again every 5 minutes.
+func WithSettingsPreflight(enabled bool) Option {
+	return func(o *options) {
+		o.settingsPreflight = enabled
+	}
+}
+
+// WithSessionStorage persists the session in storage. Call
+// Client.RestoreSession to restore the stored session.
+func WithSessionStorage(storage SessionStorage) Option {
+	return func(o *options) {
+		o.storage = storage
+	}
+}
+
+// WithStorageKey sets the key the session is stored under. It defaults to
+// "sb-<subdomain>-auth-token" like supabase-js.
+func WithStorageKey(key string) Option {
+	return func(o *options) {
+		o.storageKey = key
+	}
+}
+
+// WithLogger logs diagnostics to logger. Nothing is logged by default.
+func WithLogger(logger Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
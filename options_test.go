@@ -0,0 +1,56 @@
+package gotrue
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestNewAPIClient_options(t *testing.T) {
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	var transported bool
+	client := NewAPIClient(server.URL,
+		WithAPIKey("anon-key"),
+		WithUserAgent("gotrue-go-test"),
+		WithTimeout(time.Second),
+		WithTransport(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			transported = true
+			return http.DefaultTransport.RoundTrip(req)
+		})),
+	)
+
+	_, err := client.GetUser("user-token")
+	if err != nil {
+		t.Errorf("GetUser() error = %v", err)
+		return
+	}
+
+	if !transported {
+		t.Errorf("WithTransport() is not used")
+	}
+	if client.http.Timeout != time.Second {
+		t.Errorf("WithTimeout() is not used; timeout = %v", client.http.Timeout)
+	}
+	if got := header.Get("apikey"); got != "anon-key" {
+		t.Errorf("apikey = %s, want = anon-key", got)
+	}
+	if got := header.Get("Authorization"); got != "Bearer user-token" {
+		t.Errorf("Authorization = %s, want = Bearer user-token", got)
+	}
+	if got := header.Get("User-Agent"); got != "gotrue-go-test" {
+		t.Errorf("User-Agent = %s, want = gotrue-go-test", got)
+	}
+}
@@ -0,0 +1,84 @@
+package gotrue
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+
+	"github.com/pkg/errors"
+
+	"github.com/ulbqb/gotrue-go/gotrueapi"
+)
+
+// FlowType is how sign in through a redirect completes.
+type FlowType string
+
+const (
+	FlowTypeImplicit FlowType = "implicit"
+	FlowTypePKCE     FlowType = "pkce"
+)
+
+// codeVerifierKey returns the key the code verifier is stored under.
+func (c *Client) codeVerifierKey() string {
+	return c.storageKey + "-code-verifier"
+}
+
+// codeChallenge generates and stores a new code verifier and returns its
+// challenge. It returns an empty challenge unless the client uses the PKCE
+// flow.
+func (c *Client) codeChallenge() (string, error) {
+	if c.flowType != FlowTypePKCE {
+		return "", nil
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", errors.Wrap(err, "failed to generate code verifier")
+	}
+	verifier := base64.RawURLEncoding.EncodeToString(buf)
+
+	if err := c.verifierStorage.Set(c.codeVerifierKey(), []byte(verifier)); err != nil {
+		return "", errors.Wrap(err, "failed to store code verifier")
+	}
+
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// ExchangeCodeForSession exchanges the auth code of a PKCE redirect for a
+// session with the code verifier stored when the flow started.
+func (c *Client) ExchangeCodeForSession(code string) (*gotrueapi.Session, error) {
+	return c.ExchangeCodeForSessionWithContext(context.Background(), code)
+}
+
+// ExchangeCodeForSessionWithContext is like ExchangeCodeForSession but uses ctx for the requests.
+func (c *Client) ExchangeCodeForSessionWithContext(ctx context.Context, code string) (*gotrueapi.Session, error) {
+	verifier, err := c.verifierStorage.Get(c.codeVerifierKey())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read code verifier")
+	}
+	if len(verifier) == 0 {
+		return nil, errors.New("no code verifier was stored")
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	session, err := c.api.IssueTokenWithPKCEWithContext(ctx, &gotrueapi.TokenWithPKCEGrantParams{
+		AuthCode:     code,
+		CodeVerifier: string(verifier),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.verifierStorage.Remove(c.codeVerifierKey()); err != nil {
+		c.logf("gotrue: failed to remove code verifier: %v", err)
+	}
+
+	c.saveSession(session)
+	c.eventChannel.Publish(SignedInEvent)
+
+	return session, nil
+}
@@ -0,0 +1,88 @@
+package gotrue
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/ulbqb/gotrue-go/gotrueapi"
+)
+
+func TestClient_PKCE(t *testing.T) {
+	var challenge string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var params gotrueapi.TokenWithPKCEGrantParams
+		_ = json.NewDecoder(r.Body).Decode(&params)
+		sum := sha256.Sum256([]byte(params.CodeVerifier))
+		if r.URL.Path != "/token" || r.URL.Query().Get("grant_type") != "pkce" ||
+			params.AuthCode != "auth-code" || base64.RawURLEncoding.EncodeToString(sum[:]) != challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"code":400,"msg":"invalid flow state"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(&gotrueapi.Session{
+			Token:        "access-token",
+			TokenType:    "bearer",
+			ExpiresIn:    3600,
+			RefreshToken: "refresh-token",
+			User:         &gotrueapi.User{},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithAutoRefresh(false), WithFlowType(FlowTypePKCE))
+
+	signInURL, err := client.SignInWithProviderURL(ProviderGithub, "http://localhost/callback", "")
+	if err != nil {
+		t.Errorf("SignInWithProviderURL() error = %v", err)
+		return
+	}
+	u, err := url.Parse(signInURL)
+	if err != nil {
+		t.Errorf("url.Parse() error = %v", err)
+		return
+	}
+	challenge = u.Query().Get("code_challenge")
+	if len(challenge) == 0 || u.Query().Get("code_challenge_method") != gotrueapi.CodeChallengeMethodS256 {
+		t.Errorf("SignInWithProviderURL() = %v, want code challenge", u)
+		return
+	}
+
+	session, err := client.GetSessionFromURL("http://localhost/callback?code=auth-code", false)
+	if err != nil {
+		t.Errorf("GetSessionFromURL() error = %v", err)
+		return
+	}
+	if s := client.Session(); s == nil || s.Token != session.Token {
+		t.Errorf("GetSessionFromURL() does not save session; session = %v", s)
+	}
+
+	if _, err := client.ExchangeCodeForSession("auth-code"); err == nil {
+		t.Errorf("ExchangeCodeForSession() reuses code verifier")
+	}
+}
+
+type failingStorage struct {
+	*MemoryStorage
+}
+
+func (failingStorage) Set(key string, value []byte) error {
+	return errors.New("storage is full")
+}
+
+func TestClient_SignInWithProviderURL_storageError(t *testing.T) {
+	client := NewClient("http://localhost", WithAutoRefresh(false), WithFlowType(FlowTypePKCE),
+		WithSessionStorage(failingStorage{NewMemoryStorage()}))
+
+	if u, err := client.SignInWithProviderURL(ProviderGithub, "http://localhost/callback", ""); err == nil {
+		t.Errorf("SignInWithProviderURL() = %v, want error", u)
+	}
+	if u := client.SignInWithProvider(ProviderGithub, "http://localhost/callback", ""); len(u) > 0 {
+		t.Errorf("SignInWithProvider() = %v, want empty url", u)
+	}
+}
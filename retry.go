@@ -0,0 +1,157 @@
+package gotrue
+
+import (
+	"context"
+	"math/rand"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/ulbqb/gotrue-go/gotrueapi"
+)
+
+// RetryPolicy retries requests that failed transiently: timeouts, refused and
+// reset connections, 429 and 502-504 responses. Only requests that are safe
+// to repeat are retried, such as fetching the user or settings and refreshing
+// a session. A request is not retried if the server asks with Retry-After to
+// wait longer than MaxBackoff.
+type RetryPolicy struct {
+	// MaxAttempts is how many times a request is attempted, including the
+	// first attempt.
+	MaxAttempts int
+	// MinBackoff is the delay before the first retry. It doubles on each
+	// retry up to MaxBackoff, and is jittered.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// OnRetry is called before each retry, if set.
+	OnRetry func(info RetryInfo)
+}
+
+// RetryInfo describes a retry about to happen.
+type RetryInfo struct {
+	Request *http.Request
+	// Attempt is the number of the failed attempt, starting at 1.
+	Attempt int
+	// Delay is how long the client waits before the retry.
+	Delay time.Duration
+	Err   error
+}
+
+// DefaultRetryPolicy attempts a request up to 3 times, waiting 200ms and
+// then 400ms, or as long as the server asks with Retry-After up to 5s.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 3,
+		MinBackoff:  200 * time.Millisecond,
+		MaxBackoff:  5 * time.Second,
+	}
+}
+
+// backoff returns the delay before retrying the attempt that failed with err.
+// It returns false if the server asked to wait longer than MaxBackoff.
+func (p *RetryPolicy) backoff(attempt int, err error) (time.Duration, bool) {
+	var apiErr *gotrueapi.Error
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		if p.MaxBackoff > 0 && apiErr.RetryAfter > p.MaxBackoff {
+			return 0, false
+		}
+		return apiErr.RetryAfter, true
+	}
+
+	d := p.MinBackoff << (attempt - 1)
+	if d <= 0 || (p.MaxBackoff > 0 && d > p.MaxBackoff) {
+		d = p.MaxBackoff
+	}
+	if d <= 0 {
+		return 0, true
+	}
+	// Waits between half and the full delay so that clients failing together
+	// do not retry together.
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1)), true
+}
+
+// isTransient reports whether a request failed with err may succeed when
+// repeated.
+func isTransient(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	// The request failed before a response was received.
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+
+	var apiErr *gotrueapi.Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.Status {
+	case http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// doRetry is like do but retries transient failures by the retry policy. It
+// must only be used for requests that are safe to repeat.
+func (c *APIClient) doRetry(req *http.Request, err error) func(out interface{}) error {
+	return func(out interface{}) error {
+		_, err := c.doRetryWithHeader(req, err)(out)
+		return err
+	}
+}
+
+// doRetryWithHeader is like doRetry but also returns the response headers.
+func (c *APIClient) doRetryWithHeader(req *http.Request, err error) func(out interface{}) (http.Header, error) {
+	return func(out interface{}) (http.Header, error) {
+		p := c.retryPolicy
+		if err != nil || p == nil || p.MaxAttempts <= 1 {
+			return c.doWithHeader(req, err)(out)
+		}
+
+		ctx := req.Context()
+		for attempt := 1; ; attempt++ {
+			r := req
+			if attempt > 1 && req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, errors.Wrap(err, "api: failed to rewind request body")
+				}
+				r = req.Clone(ctx)
+				r.Body = body
+			}
+
+			header, err := c.doWithHeader(r, nil)(out)
+			if err == nil || attempt >= p.MaxAttempts || !isTransient(ctx, err) {
+				return header, err
+			}
+
+			delay, ok := p.backoff(attempt, err)
+			if !ok {
+				return header, err
+			}
+			if p.OnRetry != nil {
+				p.OnRetry(RetryInfo{Request: req, Attempt: attempt, Delay: delay, Err: err})
+			}
+
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, err
+			case <-timer.C:
+			}
+		}
+	}
+}
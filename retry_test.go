@@ -0,0 +1,120 @@
+package gotrue
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ulbqb/gotrue-go/gotrueapi"
+)
+
+func TestAPIClient_Retry(t *testing.T) {
+	var requests int32
+	retryAfter := "1"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&requests, 1)
+		switch {
+		case n == 1:
+			w.Header().Set("Retry-After", retryAfter)
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"code":429,"msg":"Too many requests"}`))
+		case n == 2:
+			w.WriteHeader(http.StatusBadGateway)
+		case r.URL.Path == "/token" && r.URL.Query().Get("grant_type") == "refresh_token":
+			var params gotrueapi.TokenWithRefreshTokenGrantParams
+			_ = json.NewDecoder(r.Body).Decode(&params)
+			if params.RefreshToken != "refresh-token" {
+				t.Errorf("retried request body = %+v", params)
+			}
+			_ = json.NewEncoder(w).Encode(&gotrueapi.Session{Token: "access-token"})
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	var retries []RetryInfo
+	policy := &RetryPolicy{
+		MaxAttempts: 3,
+		MinBackoff:  time.Millisecond,
+		MaxBackoff:  time.Second,
+		OnRetry: func(info RetryInfo) {
+			retries = append(retries, info)
+		},
+	}
+	client := NewAPIClient(server.URL, WithRetryPolicy(policy))
+
+	t.Run("refresh is retried", func(t *testing.T) {
+		session, err := client.IssueTokenWithRefreshToken(&gotrueapi.TokenWithRefreshTokenGrantParams{
+			RefreshToken: "refresh-token",
+		})
+		if err != nil {
+			t.Errorf("IssueTokenWithRefreshToken() error = %v", err)
+			return
+		}
+		if session.Token != "access-token" {
+			t.Errorf("IssueTokenWithRefreshToken() = %+v", session)
+		}
+		if len(retries) != 2 || retries[0].Delay != time.Second || retries[1].Attempt != 2 {
+			t.Errorf("OnRetry() calls = %+v, want 2 retries honoring Retry-After", retries)
+		}
+	})
+
+	t.Run("sign in is not retried", func(t *testing.T) {
+		atomic.StoreInt32(&requests, 2)
+		retries = nil
+		_, err := client.IssueTokenWithPassword(&gotrueapi.TokenWithPasswordGrantParams{
+			Email:    "user@example.com",
+			Password: "password",
+		})
+		if err == nil {
+			t.Errorf("IssueTokenWithPassword() returns no error")
+		}
+		if len(retries) != 0 || atomic.LoadInt32(&requests) != 3 {
+			t.Errorf("IssueTokenWithPassword() is retried")
+		}
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		atomic.StoreInt32(&requests, 2)
+		retries = nil
+		_, err := client.GetSettings()
+		if !isTransient(context.Background(), err) {
+			t.Errorf("GetSettings() error = %v, want 503", err)
+		}
+		if n := atomic.LoadInt32(&requests); n != 5 {
+			t.Errorf("GetSettings() made %d requests, want 3", n-2)
+		}
+	})
+
+	t.Run("gives up when Retry-After exceeds max backoff", func(t *testing.T) {
+		atomic.StoreInt32(&requests, 0)
+		retryAfter = "3600"
+		retries = nil
+		_, err := client.GetSettings()
+		if err == nil {
+			t.Errorf("GetSettings() returns no error")
+		}
+		if len(retries) != 0 || atomic.LoadInt32(&requests) != 1 {
+			t.Errorf("GetSettings() is retried after Retry-After: 3600")
+		}
+	})
+}
+
+func TestIsTransient(t *testing.T) {
+	refused := NewAPIClient("http://127.0.0.1:1")
+	_, err := refused.GetSettings()
+	if !isTransient(context.Background(), err) {
+		t.Errorf("isTransient(%v) = false, want true", err)
+	}
+
+	invalid := NewAPIClient("http://invalid host")
+	_, err = invalid.GetSettings()
+	if err == nil || isTransient(context.Background(), err) {
+		t.Errorf("isTransient(%v) = true, want false", err)
+	}
+}
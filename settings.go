@@ -0,0 +1,122 @@
+package gotrue
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/ulbqb/gotrue-go/gotrueapi"
+)
+
+// Settings fetches the server settings. The client keeps them to reject
+// requests the server would refuse without calling it.
+func (c *Client) Settings() (*gotrueapi.Settings, error) {
+	return c.SettingsWithContext(context.Background())
+}
+
+// SettingsWithContext is like Settings but uses ctx for the requests.
+func (c *Client) SettingsWithContext(ctx context.Context) (*gotrueapi.Settings, error) {
+	settings, err := c.api.GetSettingsWithContext(ctx)
+
+	c.settings.Lock()
+	defer c.settings.Unlock()
+	if err != nil {
+		c.settings.failedAt = time.Now()
+		return nil, err
+	}
+	c.settings.settings = settings
+	c.settings.fetchedAt = time.Now()
+
+	return settings, nil
+}
+
+const (
+	// settingsTTL is how long the kept settings are trusted before they are
+	// fetched again.
+	settingsTTL = 5 * time.Minute
+	// settingsRetryDelay is how long the client goes without settings after
+	// failing to fetch them.
+	settingsRetryDelay = 30 * time.Second
+)
+
+// settingsCache keeps the server settings for the preflight checks.
+type settingsCache struct {
+	sync.Mutex
+	settings  *gotrueapi.Settings
+	fetchedAt time.Time
+	failedAt  time.Time
+}
+
+// loadSettings returns the kept server settings, fetching them when they are
+// older than settingsTTL. It returns nil if they are not available or the
+// preflight checks are disabled. loadSettings must be called without holding
+// the client lock.
+func (c *Client) loadSettings(ctx context.Context) *gotrueapi.Settings {
+	if !c.settingsPreflight {
+		return nil
+	}
+
+	c.settings.Lock()
+	settings, fetchedAt, failedAt := c.settings.settings, c.settings.fetchedAt, c.settings.failedAt
+	c.settings.Unlock()
+
+	if settings != nil && time.Since(fetchedAt) < settingsTTL {
+		return settings
+	}
+	if time.Since(failedAt) < settingsRetryDelay {
+		return nil
+	}
+
+	settings, err := c.SettingsWithContext(ctx)
+	if err != nil {
+		c.logf("gotrue: failed to fetch settings: %v", err)
+		return nil
+	}
+	return settings
+}
+
+// checkProvider returns an error if the server would refuse to sign in with
+// the email or phone, or to sign up when signUp is set.
+// checkProvider must be called without holding the client lock.
+func (c *Client) checkProvider(ctx context.Context, email, phone string, signUp bool) error {
+	settings := c.loadSettings(ctx)
+	if settings == nil {
+		// Let the server decide.
+		return nil
+	}
+
+	if signUp && settings.DisableSignup {
+		return ErrSignupDisabled
+	}
+	if len(email) > 0 && providerDisabled(settings, "email") {
+		return errors.Wrap(ErrProviderDisabled, "email")
+	}
+	if len(phone) > 0 && providerDisabled(settings, "phone") {
+		return errors.Wrap(ErrProviderDisabled, "phone")
+	}
+	return nil
+}
+
+// checkExternalProvider returns an error if the server would refuse to sign in
+// with provider.
+// checkExternalProvider must be called without holding the client lock.
+func (c *Client) checkExternalProvider(ctx context.Context, provider string) error {
+	settings := c.loadSettings(ctx)
+	if settings == nil {
+		return nil
+	}
+
+	if providerDisabled(settings, provider) {
+		return errors.Wrap(ErrProviderDisabled, provider)
+	}
+	return nil
+}
+
+// providerDisabled reports whether settings lists provider as disabled.
+// Providers the server does not list are left to the server.
+func providerDisabled(settings *gotrueapi.Settings, provider string) bool {
+	enabled, ok := settings.External[provider]
+	return ok && !enabled
+}
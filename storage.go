@@ -0,0 +1,207 @@
+package gotrue
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/ulbqb/gotrue-go/gotrueapi"
+)
+
+// SessionStorage persists sessions across process restarts.
+type SessionStorage interface {
+	// Get returns the value stored under key, or nil if there is none.
+	Get(key string) ([]byte, error)
+	Set(key string, value []byte) error
+	Remove(key string) error
+}
+
+// MemoryStorage is a SessionStorage that keeps values in memory.
+type MemoryStorage struct {
+	sync.RWMutex
+	values map[string][]byte
+}
+
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{
+		values: make(map[string][]byte),
+	}
+}
+
+func (s *MemoryStorage) Get(key string) ([]byte, error) {
+	s.RLock()
+	defer s.RUnlock()
+	return s.values[key], nil
+}
+
+func (s *MemoryStorage) Set(key string, value []byte) error {
+	s.Lock()
+	defer s.Unlock()
+	s.values[key] = append([]byte(nil), value...)
+	return nil
+}
+
+func (s *MemoryStorage) Remove(key string) error {
+	s.Lock()
+	defer s.Unlock()
+	delete(s.values, key)
+	return nil
+}
+
+// FileStorage is a SessionStorage that keeps every value in a JSON file in
+// dir. The files are only readable by the owner.
+type FileStorage struct {
+	mu  sync.Mutex
+	dir string
+}
+
+func NewFileStorage(dir string) *FileStorage {
+	return &FileStorage{dir: dir}
+}
+
+func (s *FileStorage) Get(key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "storage: failed to read session")
+	}
+	return data, nil
+}
+
+func (s *FileStorage) Set(key string, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return errors.Wrap(err, "storage: failed to create directory")
+	}
+
+	// Writes to a temporary file first so that readers never see a partially
+	// written session.
+	f, err := os.CreateTemp(s.dir, ".session-*")
+	if err != nil {
+		return errors.Wrap(err, "storage: failed to create file")
+	}
+	defer os.Remove(f.Name())
+
+	if err := f.Chmod(0600); err != nil {
+		f.Close()
+		return errors.Wrap(err, "storage: failed to set file permissions")
+	}
+	if _, err := f.Write(value); err != nil {
+		f.Close()
+		return errors.Wrap(err, "storage: failed to write session")
+	}
+	if err := f.Close(); err != nil {
+		return errors.Wrap(err, "storage: failed to write session")
+	}
+
+	return errors.Wrap(os.Rename(f.Name(), s.path(key)), "storage: failed to write session")
+}
+
+func (s *FileStorage) Remove(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := os.Remove(s.path(key))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return errors.Wrap(err, "storage: failed to remove session")
+	}
+	return nil
+}
+
+func (s *FileStorage) path(key string) string {
+	return filepath.Join(s.dir, url.PathEscape(key)+".json")
+}
+
+// persistSession writes the current session to storage. Persisting is best
+// effort; the session stays usable in memory when it fails.
+// persistSession is not thread safe.
+func (c *Client) persistSession() {
+	if c.storage == nil || c.currentSession == nil {
+		return
+	}
+
+	data, err := json.Marshal(c.currentSession)
+	if err == nil {
+		err = c.storage.Set(c.storageKey, data)
+	}
+	if err != nil {
+		c.logf("gotrue: failed to persist session: %v", err)
+	}
+}
+
+// RestoreSession makes the session in storage current and returns it, or nil
+// if no session is stored. Subscribe before calling it to receive the
+// SignedInEvent of the restored session.
+func (c *Client) RestoreSession() (*gotrueapi.Session, error) {
+	return c.RestoreSessionWithContext(context.Background())
+}
+
+// RestoreSessionWithContext is like RestoreSession but uses ctx for the
+// requests. A stored session that is about to expire is refreshed before it
+// is returned.
+func (c *Client) RestoreSessionWithContext(ctx context.Context) (*gotrueapi.Session, error) {
+	if c.storage == nil {
+		return nil, nil
+	}
+
+	data, err := c.storage.Get(c.storageKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "storage: failed to read stored session")
+	}
+	if data == nil {
+		return nil, nil
+	}
+
+	var stored gotrueapi.Session
+	err = json.Unmarshal(data, &stored)
+	if err != nil || len(stored.Token) == 0 {
+		_ = c.storage.Remove(c.storageKey)
+		return nil, errors.New("storage: discarded invalid stored session")
+	}
+
+	c.Lock()
+	if !stored.IsExpired(c.refreshMargin) {
+		c.saveSession(&stored)
+		c.eventChannel.Publish(SignedInEvent)
+		s := stored
+		c.Unlock()
+		return &s, nil
+	}
+
+	if len(stored.RefreshToken) == 0 {
+		_ = c.storage.Remove(c.storageKey)
+		c.Unlock()
+		return nil, ErrSessionExpired
+	}
+
+	// The stored session is made current first, so that a rejected refresh
+	// token signs it out.
+	c.saveSession(&stored)
+	call := c.refreshSession(stored.RefreshToken, false)
+	c.Unlock()
+
+	return call.wait(ctx)
+}
+
+// defaultStorageKey returns the storage key supabase-js would use for the
+// GoTrue instance at rawURL.
+func defaultStorageKey(rawURL string) string {
+	ref := "gotrue"
+	if u, err := url.Parse(rawURL); err == nil && len(u.Hostname()) > 0 {
+		ref = strings.SplitN(u.Hostname(), ".", 2)[0]
+	}
+	return "sb-" + ref + "-auth-token"
+}
@@ -0,0 +1,148 @@
+package gotrue
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ulbqb/gotrue-go/gotrueapi"
+)
+
+func TestFileStorage(t *testing.T) {
+	storage := NewFileStorage(t.TempDir())
+
+	value, err := storage.Get("sb-test-auth-token")
+	if err != nil || value != nil {
+		t.Errorf("Get() = %s, %v; want = nil, nil", value, err)
+		return
+	}
+
+	err = storage.Set("sb-test-auth-token", []byte(`{"access_token":"token"}`))
+	if err != nil {
+		t.Errorf("Set() error = %v", err)
+		return
+	}
+
+	info, err := os.Stat(storage.path("sb-test-auth-token"))
+	if err != nil {
+		t.Errorf("Set() does not create file; error = %v", err)
+		return
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("Set() creates file with permissions %o, want = 600", perm)
+	}
+
+	value, err = storage.Get("sb-test-auth-token")
+	if err != nil || !bytes.Equal(value, []byte(`{"access_token":"token"}`)) {
+		t.Errorf("Get() = %s, %v", value, err)
+		return
+	}
+
+	err = storage.Remove("sb-test-auth-token")
+	if err != nil {
+		t.Errorf("Remove() error = %v", err)
+		return
+	}
+
+	value, err = storage.Get("sb-test-auth-token")
+	if err != nil || value != nil {
+		t.Errorf("Get() after Remove() = %s, %v; want = nil, nil", value, err)
+	}
+}
+
+func TestClient_RestoreSession(t *testing.T) {
+	storage := NewMemoryStorage()
+
+	data, _ := json.Marshal(&gotrueapi.Session{
+		Token:        "access-token",
+		TokenType:    "bearer",
+		ExpiresIn:    3600,
+		ExpiresAt:    time.Now().Add(time.Hour).Unix(),
+		RefreshToken: "refresh-token",
+	})
+	_ = storage.Set("sb-localhost-auth-token", data)
+
+	client := NewClient("http://localhost:9999", WithSessionStorage(storage))
+	defer client.StopAutoRefresh()
+
+	if s := client.Session(); s != nil {
+		t.Errorf("NewClient() restores session before RestoreSession(); got = %v", s)
+	}
+
+	var ch = make(chan struct{}, 1)
+	unsubscribe := client.Subscribe(SignedInEvent, func() {
+		ch <- struct{}{}
+	})
+	defer unsubscribe()
+
+	session, err := client.RestoreSession()
+	if err != nil {
+		t.Errorf("RestoreSession() error = %v", err)
+		return
+	}
+	if session == nil || session.Token != "access-token" {
+		t.Errorf("RestoreSession() does not restore stored session; got = %v", session)
+		return
+	}
+
+	select {
+	case <-ch:
+	case <-time.After(time.Second):
+		t.Errorf("RestoreSession() does not fire signed in event")
+	}
+
+	client.Lock()
+	client.destroySession()
+	client.Unlock()
+
+	if value, _ := storage.Get("sb-localhost-auth-token"); value != nil {
+		t.Errorf("destroySession() does not remove stored session")
+	}
+}
+
+func TestClient_RestoreSession_expired(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(&gotrueapi.Session{
+			Token:        "refreshed-access-token",
+			ExpiresIn:    3600,
+			RefreshToken: "refreshed-refresh-token",
+		})
+	}))
+	defer server.Close()
+
+	storage := NewMemoryStorage()
+	data, _ := json.Marshal(&gotrueapi.Session{
+		Token:        "access-token",
+		ExpiresAt:    time.Now().Add(-time.Minute).Unix(),
+		RefreshToken: "refresh-token",
+	})
+	_ = storage.Set("sb-test-auth-token", data)
+
+	client := NewClient(server.URL, WithAutoRefresh(false), WithSessionStorage(storage), WithStorageKey("sb-test-auth-token"))
+
+	var ch = make(chan struct{}, 1)
+	unsubscribe := client.Subscribe(TokenRefreshedEvent, func() {
+		ch <- struct{}{}
+	})
+	defer unsubscribe()
+
+	session, err := client.RestoreSessionWithContext(context.Background())
+	if err != nil {
+		t.Errorf("RestoreSessionWithContext() error = %v", err)
+		return
+	}
+	if session.Token != "refreshed-access-token" {
+		t.Errorf("RestoreSessionWithContext() = %v, want refreshed session", session)
+	}
+
+	select {
+	case <-ch:
+	case <-time.After(time.Second):
+		t.Errorf("RestoreSessionWithContext() does not fire token refreshed event")
+	}
+}
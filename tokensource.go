@@ -0,0 +1,45 @@
+package gotrue
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+
+	"github.com/ulbqb/gotrue-go/gotrueapi"
+)
+
+// TokenSource returns an oauth2.TokenSource of the session of the client.
+// Each token is the current session, refreshed first if it is about to
+// expire, so it tracks sign ins and sign outs of the client.
+func (c *Client) TokenSource() oauth2.TokenSource {
+	return &tokenSource{c: c}
+}
+
+type tokenSource struct {
+	c *Client
+}
+
+func (s *tokenSource) Token() (*oauth2.Token, error) {
+	session, err := s.c.freshSession(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	if session == nil || len(session.Token) == 0 {
+		return nil, errors.New("not signed in")
+	}
+	return tokenFromSession(session), nil
+}
+
+func tokenFromSession(session *gotrueapi.Session) *oauth2.Token {
+	token := &oauth2.Token{
+		AccessToken:  session.Token,
+		TokenType:    session.TokenType,
+		RefreshToken: session.RefreshToken,
+	}
+	if session.ExpiresAt > 0 {
+		token.Expiry = time.Unix(session.ExpiresAt, 0)
+	}
+	return token
+}
@@ -0,0 +1,60 @@
+package gotrue
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ulbqb/gotrue-go/gotrueapi"
+)
+
+func TestClient_TokenSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/token" || r.URL.Query().Get("grant_type") != "refresh_token" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(&gotrueapi.Session{
+			Token:        "new-access-token",
+			TokenType:    "bearer",
+			ExpiresIn:    3600,
+			RefreshToken: "new-refresh-token",
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithAutoRefresh(false))
+	client.Lock()
+	client.saveSession(&gotrueapi.Session{
+		Token:        "access-token",
+		TokenType:    "bearer",
+		ExpiresAt:    time.Now().Add(5 * time.Second).Unix(),
+		RefreshToken: "refresh-token",
+	})
+	client.Unlock()
+
+	ts := client.TokenSource()
+
+	token, err := ts.Token()
+	if err != nil {
+		t.Errorf("Token() error = %v", err)
+		return
+	}
+	if token.AccessToken != "new-access-token" || token.RefreshToken != "new-refresh-token" || token.Type() != "Bearer" {
+		t.Errorf("Token() = %+v, want refreshed token", token)
+	}
+	if !token.Valid() || token.Expiry.Before(time.Now().Add(59*time.Minute)) {
+		t.Errorf("Token() Expiry = %v, want in an hour", token.Expiry)
+	}
+
+	client.Lock()
+	client.destroySession()
+	client.Unlock()
+
+	if _, err := ts.Token(); err == nil {
+		t.Errorf("Token() returns no error after sign out")
+	}
+}
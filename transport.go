@@ -0,0 +1,110 @@
+package gotrue
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// Transport returns a RoundTripper that sends requests through base with the
+// access token of the current session and the API key of the client, such as
+// for calling PostgREST or Storage on behalf of the user. The session is
+// refreshed before it expires, and a request rejected with 401 is replayed
+// once with a refreshed session if its body can be replayed. base defaults to
+// http.DefaultTransport.
+func (c *Client) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{c: c, base: base, apiKey: c.api.baseHeaders["apikey"]}
+}
+
+type transport struct {
+	c      *Client
+	base   http.RoundTripper
+	apiKey string
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.c.accessToken(req.Context())
+	if err != nil {
+		closeBody(req)
+		return nil, err
+	}
+
+	resp, err := t.base.RoundTrip(t.authorize(req, token))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return resp, nil
+	}
+
+	token, err = t.c.refreshedToken(req.Context(), token)
+	if err != nil {
+		// The 401 response tells more than the refresh error.
+		t.c.logf("gotrue: failed to refresh session: %v", err)
+		return resp, nil
+	}
+
+	replay := t.authorize(req, token)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return resp, nil
+		}
+		replay.Body = body
+	}
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	return t.base.RoundTrip(replay)
+}
+
+// authorize returns a copy of req with the access token and API key.
+func (t *transport) authorize(req *http.Request, token string) *http.Request {
+	r := req.Clone(req.Context())
+	r.Header.Set("Authorization", "Bearer "+token)
+	if len(t.apiKey) > 0 && len(r.Header.Get("apikey")) == 0 {
+		r.Header.Set("apikey", t.apiKey)
+	}
+	return r
+}
+
+func closeBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
+
+// refreshedToken returns an access token to replace rejected. The session is
+// refreshed unless it was refreshed since rejected was issued.
+func (c *Client) refreshedToken(ctx context.Context, rejected string) (string, error) {
+	c.RLock()
+	current := c.currentSession
+	c.RUnlock()
+
+	if current == nil {
+		return "", errors.New("not signed in")
+	}
+	if current.Token != rejected {
+		return current.Token, nil
+	}
+	if len(current.RefreshToken) == 0 {
+		return "", errors.New("not signed in")
+	}
+
+	// Shares the refresh with concurrent requests and auto refresh.
+	c.Lock()
+	call := c.refreshSession(current.RefreshToken, false)
+	c.Unlock()
+
+	session, err := call.wait(ctx)
+	if err != nil {
+		return "", err
+	}
+	return session.Token, nil
+}
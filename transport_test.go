@@ -0,0 +1,93 @@
+package gotrue
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ulbqb/gotrue-go/gotrueapi"
+)
+
+func TestClient_Transport(t *testing.T) {
+	auth := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/token" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(&gotrueapi.Session{
+			Token:        "new-access-token",
+			ExpiresIn:    3600,
+			RefreshToken: "new-refresh-token",
+		})
+	}))
+	defer auth.Close()
+
+	rest := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("apikey") != "anon-key" {
+			t.Errorf("apikey = %q, want = anon-key", r.Header.Get("apikey"))
+		}
+		if r.Header.Get("Authorization") != "Bearer new-access-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write(body)
+	}))
+	defer rest.Close()
+
+	newClient := func() *Client {
+		client := NewClient(auth.URL, WithAutoRefresh(false), WithAPIKey("anon-key"))
+		client.Lock()
+		// The token is revoked although it has not expired.
+		client.saveSession(&gotrueapi.Session{
+			Token:        "access-token",
+			ExpiresAt:    time.Now().Add(time.Hour).Unix(),
+			RefreshToken: "refresh-token",
+		})
+		client.Unlock()
+		return client
+	}
+
+	t.Run("replays after 401", func(t *testing.T) {
+		client := newClient()
+		httpClient := &http.Client{Transport: client.Transport(nil)}
+
+		req, _ := http.NewRequest("POST", rest.URL, strings.NewReader(`{"id":1}`))
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			t.Errorf("Do() error = %v", err)
+			return
+		}
+		defer resp.Body.Close()
+
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusOK || string(body) != `{"id":1}` {
+			t.Errorf("Do() = %d %s, want replayed request", resp.StatusCode, body)
+		}
+		if s := client.Session(); s == nil || s.Token != "new-access-token" {
+			t.Errorf("Transport() does not refresh session; session = %v", s)
+		}
+	})
+
+	t.Run("does not replay unreplayable body", func(t *testing.T) {
+		client := newClient()
+		httpClient := &http.Client{Transport: client.Transport(nil)}
+
+		req, _ := http.NewRequest("POST", rest.URL, io.NopCloser(strings.NewReader(`{"id":1}`)))
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			t.Errorf("Do() error = %v", err)
+			return
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Do() status = %d, want = %d", resp.StatusCode, http.StatusUnauthorized)
+		}
+	})
+}
@@ -20,10 +20,11 @@ const (
 type AuthChangeEvent string
 
 const (
-	PasswordRecoveryEvent AuthChangeEvent = "PASSWORD_RECOVERY"
-	SignedInEvent         AuthChangeEvent = "SIGNED_IN"
-	SignedOutEvent        AuthChangeEvent = "SIGNED_OUT"
-	TokenRefreshedEvent   AuthChangeEvent = "TOKEN_REFRESHED"
-	UserDeletedEvent      AuthChangeEvent = "USER_DELETED"
-	UserUpdatedEvent      AuthChangeEvent = "USER_UPDATED"
+	PasswordRecoveryEvent     AuthChangeEvent = "PASSWORD_RECOVERY"
+	SignedInEvent             AuthChangeEvent = "SIGNED_IN"
+	SignedOutEvent            AuthChangeEvent = "SIGNED_OUT"
+	TokenRefreshedEvent       AuthChangeEvent = "TOKEN_REFRESHED"
+	UserDeletedEvent          AuthChangeEvent = "USER_DELETED"
+	UserUpdatedEvent          AuthChangeEvent = "USER_UPDATED"
+	MFAChallengeVerifiedEvent AuthChangeEvent = "MFA_CHALLENGE_VERIFIED"
 )
@@ -0,0 +1,226 @@
+package verifier
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// jwksTTL is how long fetched keys are used before fetching them again.
+	jwksTTL = 10 * time.Minute
+	// jwksMinRefetchInterval limits fetches caused by unknown key ids and
+	// failures.
+	jwksMinRefetchInterval = 30 * time.Second
+)
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+
+	// EC
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jwksCache struct {
+	mu          sync.RWMutex
+	url         string
+	http        *http.Client
+	keys        map[string]interface{}
+	fetchedAt   time.Time
+	attemptedAt time.Time
+	// fetchErr is the error of the last fetch.
+	fetchErr error
+	// fetching is closed when the fetch in flight completes.
+	fetching chan struct{}
+}
+
+func newJWKSCache(url string, client *http.Client) *jwksCache {
+	return &jwksCache{
+		url:  url,
+		http: client,
+	}
+}
+
+// key returns the public key with kid for alg, fetching the JWKS if the
+// cache is stale or does not know kid.
+func (c *jwksCache) key(ctx context.Context, kid, alg string) (interface{}, error) {
+	c.mu.RLock()
+	_, known := c.keys[kid]
+	// Waits for a fetch in flight unless kid is already known.
+	refetch := c.needsFetch(kid) || (!known && c.fetching != nil)
+	c.mu.RUnlock()
+
+	if refetch {
+		// Keeps using the cached keys if the JWKS is unavailable.
+		if err := c.refresh(ctx, kid); err != nil {
+			return nil, err
+		}
+	}
+
+	c.mu.RLock()
+	keys, fetchErr := c.keys, c.fetchErr
+	c.mu.RUnlock()
+	if keys == nil && fetchErr != nil {
+		return nil, fetchErr
+	}
+
+	key, ok := keys[kid]
+	if !ok {
+		return nil, errors.Errorf("unknown key id %q", kid)
+	}
+
+	switch key.(type) {
+	case *rsa.PublicKey:
+		if alg != "RS256" {
+			return nil, errors.Errorf("key %q cannot verify %s", kid, alg)
+		}
+	case *ecdsa.PublicKey:
+		if alg != "ES256" {
+			return nil, errors.Errorf("key %q cannot verify %s", kid, alg)
+		}
+	}
+
+	return key, nil
+}
+
+// needsFetch reports whether the JWKS should be fetched to find kid. It is
+// not thread safe.
+func (c *jwksCache) needsFetch(kid string) bool {
+	_, known := c.keys[kid]
+	stale := time.Since(c.fetchedAt) > jwksTTL
+	return (stale || !known) && time.Since(c.attemptedAt) > jwksMinRefetchInterval
+}
+
+// refresh fetches the JWKS unless another fetch is in flight, in which case
+// it waits for that one. It only returns an error if ctx is done while
+// waiting; the result of the fetch is kept in the cache.
+func (c *jwksCache) refresh(ctx context.Context, kid string) error {
+	c.mu.Lock()
+	fetching := c.fetching
+	if fetching == nil && c.needsFetch(kid) {
+		fetching = make(chan struct{})
+		c.fetching = fetching
+		c.attemptedAt = time.Now()
+		c.mu.Unlock()
+
+		keys, err := c.fetch(ctx)
+
+		c.mu.Lock()
+		if err == nil {
+			c.keys = keys
+			c.fetchedAt = time.Now()
+		}
+		c.fetchErr = err
+		c.fetching = nil
+		close(fetching)
+		c.mu.Unlock()
+	} else {
+		c.mu.Unlock()
+		if fetching != nil {
+			select {
+			case <-fetching:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+	return nil
+}
+
+// fetch fetches the JWKS and returns the signing keys it contains.
+func (c *jwksCache) fetch(ctx context.Context) (map[string]interface{}, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "verifier: failed to create jwks request")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "verifier: failed to fetch jwks")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("verifier: failed to fetch jwks (%d)", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, errors.Wrap(err, "verifier: failed to decode jwks")
+	}
+
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, k := range set.Keys {
+		if len(k.Use) > 0 && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			// Keys of unsupported types are skipped.
+			continue
+		}
+		keys[k.Kid] = key
+	}
+
+	return keys, nil
+}
+
+func (k *jwk) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, errors.Errorf("unsupported curve %s", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+
+	default:
+		return nil, errors.Errorf("unsupported key type %s", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid key parameter")
+	}
+	return new(big.Int).SetBytes(data), nil
+}
@@ -0,0 +1,156 @@
+// Package verifier validates GoTrue access tokens locally, without calling
+// the GoTrue server for every request.
+package verifier
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pkg/errors"
+
+	"github.com/ulbqb/gotrue-go/gotrueapi"
+)
+
+// DefaultAudience is the audience GoTrue issues tokens for signed in users.
+const DefaultAudience = "authenticated"
+
+var (
+	// ErrInvalidToken is returned when a token is malformed, is not signed
+	// by a trusted key or has invalid claims.
+	ErrInvalidToken = errors.New("verifier: invalid token")
+	// ErrTokenExpired is returned when a token has expired.
+	ErrTokenExpired = errors.New("verifier: token is expired")
+)
+
+// Verifier validates access tokens signed with a shared secret (HS256) or
+// with a key published in a JWKS (RS256, ES256).
+type Verifier struct {
+	secret   []byte
+	jwks     *jwksCache
+	audience string
+	issuer   string
+	leeway   time.Duration
+}
+
+// Option configures a Verifier.
+type Option func(*Verifier)
+
+// WithSecret trusts HS256 tokens signed with secret, the JWT secret of GoTrue.
+func WithSecret(secret []byte) Option {
+	return func(v *Verifier) {
+		v.secret = secret
+	}
+}
+
+// WithJWKS trusts RS256 and ES256 tokens signed with a key from the JWKS at
+// jwksURL. The keys are cached and fetched again when a token is signed with
+// an unknown key.
+func WithJWKS(jwksURL string, client *http.Client) Option {
+	return func(v *Verifier) {
+		if client == nil {
+			client = &http.Client{Timeout: 5 * time.Second}
+		}
+		v.jwks = newJWKSCache(jwksURL, client)
+	}
+}
+
+// WithGoTrueJWKS is WithJWKS with the JWKS endpoint of the GoTrue server at
+// url.
+func WithGoTrueJWKS(url string, client *http.Client) Option {
+	return WithJWKS(strings.TrimSuffix(url, "/")+"/.well-known/jwks.json", client)
+}
+
+// WithAudience sets the required audience. It defaults to DefaultAudience.
+// An empty audience disables the check.
+func WithAudience(audience string) Option {
+	return func(v *Verifier) {
+		v.audience = audience
+	}
+}
+
+// WithIssuer sets the required issuer. The issuer is not checked by default.
+func WithIssuer(issuer string) Option {
+	return func(v *Verifier) {
+		v.issuer = issuer
+	}
+}
+
+// WithLeeway allows for clock skew between GoTrue and this process when
+// checking the expiry.
+func WithLeeway(leeway time.Duration) Option {
+	return func(v *Verifier) {
+		v.leeway = leeway
+	}
+}
+
+func New(opts ...Option) *Verifier {
+	v := &Verifier{
+		audience: DefaultAudience,
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+// Verify validates token and returns its claims. ctx is used when the JWKS
+// has to be fetched.
+func (v *Verifier) Verify(ctx context.Context, token string) (*gotrueapi.Claims, error) {
+	var claims gotrueapi.Claims
+
+	parser := jwt.NewParser(
+		jwt.WithValidMethods([]string{"HS256", "RS256", "ES256"}),
+		// Time based claims are checked below to take the leeway into account.
+		jwt.WithoutClaimsValidation(),
+	)
+	_, err := parser.ParseWithClaims(token, &claims, func(t *jwt.Token) (interface{}, error) {
+		return v.key(ctx, t)
+	})
+	if err != nil {
+		return nil, invalidToken(err.Error())
+	}
+
+	now := time.Now()
+	if claims.ExpiresAt == nil {
+		return nil, invalidToken("exp is missing")
+	}
+	if !claims.VerifyExpiresAt(now.Add(-v.leeway), true) {
+		return nil, ErrTokenExpired
+	}
+	if !claims.VerifyNotBefore(now.Add(v.leeway), false) {
+		return nil, invalidToken("token is not valid yet")
+	}
+	if len(v.audience) > 0 && !claims.VerifyAudience(v.audience, true) {
+		return nil, invalidToken("aud is invalid")
+	}
+	if len(v.issuer) > 0 && !claims.VerifyIssuer(v.issuer, true) {
+		return nil, invalidToken("iss is invalid")
+	}
+
+	return &claims, nil
+}
+
+func (v *Verifier) key(ctx context.Context, t *jwt.Token) (interface{}, error) {
+	switch t.Method.Alg() {
+	case "HS256":
+		if len(v.secret) == 0 {
+			return nil, errors.New("HS256 tokens are not trusted")
+		}
+		return v.secret, nil
+
+	default:
+		if v.jwks == nil {
+			return nil, errors.Errorf("%s tokens are not trusted", t.Method.Alg())
+		}
+		kid, _ := t.Header["kid"].(string)
+		return v.jwks.key(ctx, kid, t.Method.Alg())
+	}
+}
+
+func invalidToken(reason string) error {
+	return fmt.Errorf("%w: %s", ErrInvalidToken, reason)
+}
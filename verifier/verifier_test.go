@@ -0,0 +1,140 @@
+package verifier
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pkg/errors"
+
+	"github.com/ulbqb/gotrue-go/gotrueapi"
+	"github.com/ulbqb/gotrue-go/internal/testdata"
+)
+
+func mockClaims(expiresIn time.Duration) *gotrueapi.Claims {
+	return &gotrueapi.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "1234567890",
+			Audience:  jwt.ClaimStrings{DefaultAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiresIn)),
+		},
+		Email:       testdata.MockUserEmail(),
+		Role:        "authenticated",
+		AppMetadata: testdata.MockAppMetadata(),
+		AAL:         "aal1",
+		SessionID:   "session-id",
+	}
+}
+
+func TestVerifier_HS256(t *testing.T) {
+	v := New(WithSecret(testdata.GotrueJWTSecret))
+
+	sign := func(claims *gotrueapi.Claims, secret []byte) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	t.Run("valid token", func(t *testing.T) {
+		want := mockClaims(time.Hour)
+		claims, err := v.Verify(context.Background(), sign(want, testdata.GotrueJWTSecret))
+		if err != nil {
+			t.Errorf("Verify() error = %v", err)
+			return
+		}
+		if claims.Subject != want.Subject || claims.Email != want.Email || claims.SessionID != want.SessionID {
+			t.Errorf("Verify() = %+v, want = %+v", claims, want)
+		}
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		_, err := v.Verify(context.Background(), sign(mockClaims(-time.Minute), testdata.GotrueJWTSecret))
+		if !errors.Is(err, ErrTokenExpired) {
+			t.Errorf("Verify() error = %v, want = %v", err, ErrTokenExpired)
+		}
+	})
+
+	t.Run("wrong audience", func(t *testing.T) {
+		claims := mockClaims(time.Hour)
+		claims.Audience = jwt.ClaimStrings{"other"}
+		_, err := v.Verify(context.Background(), sign(claims, testdata.GotrueJWTSecret))
+		if !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Verify() error = %v, want = %v", err, ErrInvalidToken)
+		}
+	})
+
+	t.Run("wrong secret", func(t *testing.T) {
+		_, err := v.Verify(context.Background(), sign(mockClaims(time.Hour), []byte("wrong-secret")))
+		if !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Verify() error = %v, want = %v", err, ErrInvalidToken)
+		}
+	})
+}
+
+func TestVerifier_JWKS(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var fetches int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/.well-known/jwks.json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		atomic.AddInt32(&fetches, 1)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "EC",
+				"kid": "key-1",
+				"use": "sig",
+				"crv": "P-256",
+				"x":   base64.RawURLEncoding.EncodeToString(key.X.Bytes()),
+				"y":   base64.RawURLEncoding.EncodeToString(key.Y.Bytes()),
+			}},
+		})
+	}))
+	defer server.Close()
+
+	v := New(WithGoTrueJWKS(server.URL, nil))
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, mockClaims(time.Hour))
+	token.Header["kid"] = "key-1"
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := v.Verify(context.Background(), signed); err != nil {
+				t.Errorf("Verify() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := atomic.LoadInt32(&fetches); got != 1 {
+		t.Errorf("JWKS fetched %d times, want = 1", got)
+	}
+
+	_, err = v.Verify(context.Background(), testdata.MockAccessToken())
+	if !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Verify() of HS256 token error = %v, want = %v", err, ErrInvalidToken)
+	}
+}